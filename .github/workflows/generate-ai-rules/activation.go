@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Activation modes of a rule group (RuleGroup.Activation), which decide when an assistant
+// loads the rule. Each target maps them to its closest native construct.
+const (
+	activationAlways         = "always"          // loaded into every session
+	activationGlob           = "glob"            // loaded when files matching the globs are involved
+	activationAgentRequested = "agent-requested" // loaded when the assistant finds the description relevant
+	activationManual         = "manual"          // loaded only when the user asks for it
+)
+
+// activations are the accepted values of RuleGroup.Activation besides the empty default.
+var activations = []string{activationAlways, activationGlob, activationAgentRequested, activationManual}
+
+// activation returns how the group is activated. Without an explicit activation, groups
+// with globs are glob-activated and all other groups always apply.
+func (g RuleGroup) activation() string {
+	switch {
+	case g.Activation != "":
+		return g.Activation
+	case len(g.Globs) > 0:
+		return activationGlob
+	}
+	return activationAlways
+}
+
+// onDemand reports whether the group is loaded only when the assistant or the user asks for it,
+// so it must stay out of the files every session reads.
+func (g RuleGroup) onDemand() bool {
+	activation := g.activation()
+	return activation == activationAgentRequested || activation == activationManual
+}
+
+// validateActivation checks the activation of a group against its globs: the glob activation
+// needs globs, and globs mean nothing to the other activations. field prefixes each error.
+func validateActivation(group RuleGroup, field string) error {
+	if group.Activation != "" && !slices.Contains(activations, group.Activation) {
+		return fmt.Errorf("%s.activation %q must be one of %s", field, group.Activation, strings.Join(activations, ", "))
+	}
+	activation := group.activation()
+	if activation == activationGlob && len(group.Globs) == 0 {
+		return fmt.Errorf("%s.activation %q needs globs", field, activation)
+	}
+	if activation != activationGlob && len(group.Globs) > 0 {
+		return fmt.Errorf("%s.globs only apply to the %q activation, not %q", field, activationGlob, activation)
+	}
+	return nil
+}
+
+// formatRuleIndex lists rule files an assistant reads on demand, for targets whose context
+// files are loaded whole. path gives the listed path of a group's file. Manual groups are
+// marked so the assistant waits to be asked. It returns an empty string when nothing is listed.
+func formatRuleIndex(groups []RuleGroup, indexes []int, path func(RuleGroup) string) string {
+	if len(indexes) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("## Additional Rules\n\n")
+	sb.WriteString("Read the linked file before working on the matching files or topics:\n\n")
+	for _, i := range indexes {
+		sb.WriteString(fmt.Sprintf("- `%s`: %s", path(groups[i]), groups[i].Description))
+		switch groups[i].activation() {
+		case activationGlob:
+			sb.WriteString(fmt.Sprintf(" (files matching %s)", groups[i].Globs.describe()))
+		case activationManual:
+			sb.WriteString(" (only when the user asks for it)")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
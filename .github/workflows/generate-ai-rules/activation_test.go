@@ -0,0 +1,113 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRuleGroupActivation(t *testing.T) {
+	tests := []struct {
+		name             string
+		group            RuleGroup
+		expected         string
+		expectedOnDemand bool
+	}{
+		{name: "always by default", group: RuleGroup{}, expected: activationAlways},
+		{name: "glob when globs are set", group: RuleGroup{Globs: GlobList{"**/*.go"}}, expected: activationGlob},
+		{
+			name:             "explicit agent-requested",
+			group:            RuleGroup{Activation: activationAgentRequested},
+			expected:         activationAgentRequested,
+			expectedOnDemand: true,
+		},
+		{
+			name:             "explicit manual",
+			group:            RuleGroup{Activation: activationManual},
+			expected:         activationManual,
+			expectedOnDemand: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			activation := tt.group.activation()
+			onDemand := tt.group.onDemand()
+
+			// then
+			if activation != tt.expected || onDemand != tt.expectedOnDemand {
+				t.Errorf("activation() = %q, onDemand() = %v, want %q, %v", activation, onDemand, tt.expected, tt.expectedOnDemand)
+			}
+		})
+	}
+}
+
+func TestValidateActivation(t *testing.T) {
+	tests := []struct {
+		name        string
+		group       RuleGroup
+		expectError string
+	}{
+		{name: "default without globs", group: RuleGroup{}},
+		{name: "glob with globs", group: RuleGroup{Activation: activationGlob, Globs: GlobList{"**/*.go"}}},
+		{name: "manual without globs", group: RuleGroup{Activation: activationManual}},
+		{
+			name:        "unknown activation",
+			group:       RuleGroup{Activation: "sometimes"},
+			expectError: `groups[0].activation "sometimes" must be one of always, glob, agent-requested, manual`,
+		},
+		{
+			name:        "glob without globs",
+			group:       RuleGroup{Activation: activationGlob},
+			expectError: `groups[0].activation "glob" needs globs`,
+		},
+		{
+			name:        "globs on an agent-requested group",
+			group:       RuleGroup{Activation: activationAgentRequested, Globs: GlobList{"**/*.go"}},
+			expectError: `groups[0].globs only apply to the "glob" activation, not "agent-requested"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			err := validateActivation(tt.group, "groups[0]")
+
+			// then
+			if tt.expectError == "" {
+				if err != nil {
+					t.Errorf("validateActivation() error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("validateActivation() error = %v, want error containing %q", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestFormatRuleIndex(t *testing.T) {
+	// given
+	groups := []RuleGroup{
+		{Name: "golang", Description: "Go standards", Globs: GlobList{"**/*.go", "!**/*_mock.go"}},
+		{Name: "bulk-operations", Description: "Bulk operations", Activation: activationAgentRequested},
+		{Name: "release", Description: "Release checklist", Activation: activationManual},
+	}
+
+	// when
+	index := formatRuleIndex(groups, []int{0, 1, 2}, func(group RuleGroup) string { return group.Name + ".md" })
+	empty := formatRuleIndex(groups, nil, func(group RuleGroup) string { return group.Name + ".md" })
+
+	// then
+	expected := "## Additional Rules\n\nRead the linked file before working on the matching files or topics:\n\n" +
+		"- `golang.md`: Go standards (files matching `**/*.go`, excluding `**/*_mock.go`)\n" +
+		"- `bulk-operations.md`: Bulk operations\n" +
+		"- `release.md`: Release checklist (only when the user asks for it)\n"
+	if index != expected {
+		t.Errorf("formatRuleIndex()\n  got:  %q\n  want: %q", index, expected)
+	}
+	if empty != "" {
+		t.Errorf("formatRuleIndex() without groups = %q, want an empty string", empty)
+	}
+}
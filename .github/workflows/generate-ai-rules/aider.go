@@ -0,0 +1,73 @@
+package main
+
+import "strings"
+
+// aiderTarget emits a single CONVENTIONS.md, the conventions file Aider loads with --read.
+// Agent-requested and manual groups are written to aider/rules/ instead and listed at the
+// end of CONVENTIONS.md, so they are only added to the chat when needed.
+type aiderTarget struct{}
+
+func (aiderTarget) Name() string    { return "aider" }
+func (aiderTarget) Paths() []string { return []string{"aider/CONVENTIONS.md", "aider/rules"} }
+
+// Reference points to the group's section, since every group shares CONVENTIONS.md,
+// or to the file of a group loaded on demand.
+func (aiderTarget) Reference(group RuleGroup) string {
+	if group.onDemand() {
+		return "see " + strings.TrimPrefix(aiderRulePath(group), "aider/")
+	}
+	return "see the \"" + group.Description + "\" section"
+}
+
+func (aiderTarget) RenderGroup(RuleGroup, string) []renderedFile { return nil }
+
+func (aiderTarget) RenderAggregate(groups []RuleGroup, contents []string) ([]renderedFile, error) {
+	return renderAider(groups, contents), nil
+}
+
+// renderAider renders aider/CONVENTIONS.md by concatenating all non-empty rule groups that
+// are not loaded on demand, followed by an index of aider/rules/<name>.md for those that are.
+// Aider has no glob activation, so language groups state the files they apply to.
+func renderAider(groups []RuleGroup, contents []string) []renderedFile {
+	var sb strings.Builder
+	var inlined, listed []int
+	for i, group := range groups {
+		if contents[i] == "" {
+			continue
+		}
+		if group.onDemand() {
+			listed = append(listed, i)
+			continue
+		}
+		inlined = append(inlined, i)
+		if sb.Len() > 0 {
+			sb.WriteString("\n---\n\n")
+		}
+		sb.WriteString(formatGlobNotice(group.Globs))
+		sb.WriteString(contents[i])
+	}
+	if index := formatRuleIndex(groups, listed, func(group RuleGroup) string {
+		return strings.TrimPrefix(aiderRulePath(group), "aider/")
+	}); index != "" {
+		if sb.Len() > 0 {
+			sb.WriteString("\n---\n\n")
+		}
+		sb.WriteString(index)
+	}
+
+	files := []renderedFile{{Target: "aider", Path: "aider/CONVENTIONS.md", Body: sb.String(), Sources: groupSources(groups, inlined)}}
+	for _, i := range listed {
+		files = append(files, renderedFile{
+			Target:  "aider",
+			Path:    aiderRulePath(groups[i]),
+			Body:    contents[i],
+			Sources: groups[i].Sources,
+		})
+	}
+	return files
+}
+
+// aiderRulePath returns the path of the file an on-demand group is written to for Aider.
+func aiderRulePath(group RuleGroup) string {
+	return "aider/rules/" + group.Name + ".md"
+}
@@ -0,0 +1,56 @@
+package main
+
+import "testing"
+
+func TestRenderAider(t *testing.T) {
+	// given
+	groups := []RuleGroup{
+		{Name: "code-style", Description: "Code style"},
+		{Name: "empty", Description: "Empty group"},
+		{Name: "golang", Description: "Go standards", Globs: GlobList{"**/*.go"}},
+	}
+	contents := []string{"# Code Style\n", "", "# Go\n"}
+
+	// when
+	files := renderAider(groups, contents)
+
+	// then
+	expected := "# Code Style\n\n---\n\n> Applies to files matching `**/*.go`.\n\n# Go\n"
+	if len(files) != 1 || files[0].Path != "aider/CONVENTIONS.md" {
+		t.Fatalf("files = %v, want only aider/CONVENTIONS.md", files)
+	}
+	if files[0].Body != expected {
+		t.Errorf("body\n  got:  %q\n  want: %q", files[0].Body, expected)
+	}
+}
+
+func TestRenderAiderListsOnDemandGroups(t *testing.T) {
+	// given
+	groups := []RuleGroup{
+		{Name: "code-style", Description: "Code style"},
+		{Name: "bulk-operations", Description: "Bulk operations", Activation: activationAgentRequested},
+		{Name: "release", Description: "Release checklist", Activation: activationManual},
+	}
+	contents := []string{"# Code Style\n", "# Bulk\n", "# Release\n"}
+
+	// when
+	files := renderAider(groups, contents)
+
+	// then
+	expected := "# Code Style\n\n---\n\n## Additional Rules\n\n" +
+		"Read the linked file before working on the matching files or topics:\n\n" +
+		"- `rules/bulk-operations.md`: Bulk operations\n" +
+		"- `rules/release.md`: Release checklist (only when the user asks for it)\n"
+	if len(files) != 3 {
+		t.Fatalf("got %d files, want CONVENTIONS.md and two rule files", len(files))
+	}
+	if files[0].Body != expected {
+		t.Errorf("CONVENTIONS.md\n  got:  %q\n  want: %q", files[0].Body, expected)
+	}
+	if files[1].Path != "aider/rules/bulk-operations.md" || files[1].Body != "# Bulk\n" {
+		t.Errorf("files[1] = %+v, want the bulk-operations content at aider/rules/bulk-operations.md", files[1])
+	}
+	if files[2].Path != "aider/rules/release.md" {
+		t.Errorf("files[2].Path = %q, want aider/rules/release.md", files[2].Path)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"slices"
+	"strings"
+
+	logger "github.com/sirupsen/logrus"
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// Rendering modes of a rule group (RuleGroup.Mode). The default is the full merged content.
+const (
+	modeFull    = "full"    // the merged pages, as written
+	modeCompact = "compact" // a checklist condensed from the merged pages replaces them
+	modeBoth    = "both"    // the full group plus an always-apply checklist that refers to it
+)
+
+// renderModes are the accepted values of RuleGroup.Mode besides the empty default.
+var renderModes = []string{modeFull, modeCompact, modeBoth}
+
+// checklistSuffix is appended to a group name to name its checklist in the "both" mode.
+const checklistSuffix = "-checklist"
+
+// requirementRegex matches the RFC 2119 style keywords that make a sentence a rule.
+var requirementRegex = regexp.MustCompile(`(?i)\b(must|must not|shall|should|should not|required|always|never|do not)\b`)
+
+// tldrRegex matches the opening of a TL;DR blockquote, such as "> **TL;DR:** ...".
+var tldrRegex = regexp.MustCompile(`^\**TL;DR`)
+
+// applyRenderModes condenses the groups that ask for a checklist. Compact groups have their
+// content replaced, while groups in the "both" mode keep it and get an always-apply
+// "<name>-checklist" group that refers each target to the full rule.
+func applyRenderModes(selected []Target, groups []RuleGroup, contents ruleContents) ([]RuleGroup, ruleContents) {
+	expanded := make([]RuleGroup, 0, len(groups))
+	result := make(ruleContents, len(contents))
+	for i, group := range groups {
+		expanded = append(expanded, group)
+		for _, target := range selected {
+			name := target.Name()
+			content := contents[name][i]
+			if group.Mode == modeCompact {
+				if compact := compactContent(group, content); compact != "" {
+					content = compact
+				} else if content != "" {
+					logger.WithFields(logger.Fields{
+						"group":  group.Name,
+						"target": name,
+					}).Warn("found nothing to condense, keeping the full content")
+				}
+			}
+			result[name] = append(result[name], content)
+		}
+		if group.Mode != modeBoth {
+			continue
+		}
+
+		checklist := RuleGroup{
+			Name:        group.Name + checklistSuffix,
+			Description: group.Description + " (checklist)",
+			Sources:     group.Sources,
+			Priority:    group.Priority,
+		}
+		expanded = append(expanded, checklist)
+		for _, target := range selected {
+			name := target.Name()
+			compact := compactContent(checklist, contents[name][i])
+			if compact != "" {
+				compact += "\nComplete rules, with examples and rationale: " + target.Reference(group) + ".\n"
+			}
+			result[name] = append(result[name], compact)
+		}
+	}
+	return expanded, result
+}
+
+// compactContent condenses merged group content into a checklist titled after group.
+// It keeps the section headings (H2 and H3) above anything kept, TL;DR blockquotes and
+// tables verbatim, and every sentence with a requirement keyword as a bullet. Code,
+// examples, and explanations are left out. It returns an empty string when nothing is kept.
+func compactContent(group RuleGroup, content string) string {
+	source := []byte(content)
+	doc := markdown.Parser().Parse(text.NewReader(source))
+
+	var blocks []string
+	var headings [2]string // pending H2 and H3 headings, emitted before the next kept block
+	var seen []string
+	keep := func(block string) {
+		for _, heading := range headings {
+			if heading != "" {
+				blocks = append(blocks, heading)
+			}
+		}
+		headings = [2]string{}
+		blocks = append(blocks, block)
+	}
+
+	var bullets []string
+	flush := func() {
+		if len(bullets) > 0 {
+			keep(strings.Join(bullets, "\n"))
+			bullets = nil
+		}
+	}
+
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := node.(type) {
+		case *ast.Heading:
+			// deeper headings are dropped, so their rules join the list of their section
+			switch n.Level {
+			case 2:
+				flush()
+				headings = [2]string{"## " + inlineText(n, source), ""}
+			case 3:
+				flush()
+				headings[1] = "### " + inlineText(n, source)
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.Blockquote:
+			if !tldrRegex.MatchString(strings.TrimSpace(inlineText(n, source))) {
+				return ast.WalkContinue, nil
+			}
+			flush()
+			keep(string(bytes.TrimSpace(source[blockStart(source, n):blockEnd(source, n)])))
+			return ast.WalkSkipChildren, nil
+		case *east.Table:
+			flush()
+			keep(string(bytes.TrimSpace(source[blockStart(source, n):blockEnd(source, n)])))
+			return ast.WalkSkipChildren, nil
+		case *ast.FencedCodeBlock, *ast.CodeBlock, *ast.HTMLBlock:
+			return ast.WalkSkipChildren, nil
+		case *ast.Paragraph, *ast.TextBlock:
+			for _, sentence := range splitSentences(blockText(n, source)) {
+				if requirementRegex.MatchString(sentence) && !slices.Contains(seen, sentence) {
+					seen = append(seen, sentence)
+					bullets = append(bullets, "- "+sentence)
+				}
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	flush()
+
+	if len(blocks) == 0 {
+		return ""
+	}
+	return "# " + group.Description + "\n\n" + strings.Join(blocks, "\n\n") + "\n"
+}
+
+// blockText returns the markdown of a paragraph on a single line.
+func blockText(node ast.Node, source []byte) string {
+	var parts []string
+	lines := node.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		if trimmed := strings.TrimSpace(string(line.Value(source))); trimmed != "" {
+			parts = append(parts, trimmed)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// splitSentences splits markdown text after every ".", "!", or "?" that ends a word,
+// except inside code spans, so "`go test ./...` must pass." stays one sentence.
+func splitSentences(line string) []string {
+	var sentences []string
+	inCode := false
+	start := 0
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '`':
+			inCode = !inCode
+		case !inCode && (c == '.' || c == '!' || c == '?'):
+			// keep closing emphasis with its sentence, as in "**Never do this.**"
+			end := i + 1
+			for end < len(line) && (line[end] == '*' || line[end] == '_') {
+				end++
+			}
+			if end < len(line) && line[end] != ' ' || isAbbreviation(line[start:end]) {
+				continue
+			}
+			sentences = append(sentences, strings.TrimSpace(line[start:end]))
+			start, i = end, end-1
+		}
+	}
+	if rest := strings.TrimSpace(line[start:]); rest != "" {
+		sentences = append(sentences, rest)
+	}
+	return sentences
+}
+
+// isAbbreviation reports whether text ends with an abbreviation such as "e.g." rather than a sentence.
+func isAbbreviation(text string) bool {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return false
+	}
+	last := strings.ToLower(strings.TrimLeft(words[len(words)-1], "(*_"))
+	return last == "e.g." || last == "i.e." || last == "vs."
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// updateGolden rewrites the golden files from the current documentation:
+// go test -run TestChecklistGolden -update
+var updateGolden = flag.Bool("update", false, "rewrite the golden files under testdata")
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "sentences split after punctuation",
+			input:    "Always quote strings. Never use tabs! Why?",
+			expected: []string{"Always quote strings.", "Never use tabs!", "Why?"},
+		},
+		{
+			name:     "code spans and closing emphasis kept together",
+			input:    "**Run `go test ./...` first.** Then push.",
+			expected: []string{"**Run `go test ./...` first.**", "Then push."},
+		},
+		{
+			name:     "abbreviations do not end sentences",
+			input:    "Use a tool (e.g. `yamllint`) on every file.",
+			expected: []string{"Use a tool (e.g. `yamllint`) on every file."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			result := splitSentences(tt.input)
+
+			// then
+			if strings.Join(result, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("splitSentences(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompactContent(t *testing.T) {
+	// given
+	group := RuleGroup{Name: "yaml", Description: "YAML standards"}
+	content := "# YAML standards\n\n## YAML\n\n" +
+		"> **TL;DR:** Always quote strings.\n\n" +
+		"### Overview\n\nYAML is a data format. It is used for configuration.\n\n" +
+		"### Quoting\n\nStrings are quoted. Values must use single quotes.\n\n" +
+		"```yaml\nname: 'app' # you must quote\n```\n\n" +
+		"#### Exceptions\n\n- Never quote booleans.\n\n" +
+		"| Type | Style |\n|---|---|\n| string | `'a'` |\n"
+
+	// when
+	result := compactContent(group, content)
+
+	// then
+	expected := "# YAML standards\n\n## YAML\n\n" +
+		"> **TL;DR:** Always quote strings.\n\n" +
+		"### Quoting\n\n- Values must use single quotes.\n- Never quote booleans.\n\n" +
+		"| Type | Style |\n|---|---|\n| string | `'a'` |\n"
+	if result != expected {
+		t.Errorf("compactContent():\ngot:\n%s\nwant:\n%s", result, expected)
+	}
+	if compactContent(group, "# YAML standards\n\nYAML is a data format.\n") != "" {
+		t.Error("compactContent() should be empty when no rule is found")
+	}
+}
+
+func TestApplyRenderModes(t *testing.T) {
+	// given
+	selected := []Target{claudeTarget{}}
+	groups := []RuleGroup{
+		{Name: "yaml", Description: "YAML", Globs: GlobList{"**/*.yaml"}, Mode: modeBoth},
+		{Name: "go", Description: "Go", Globs: GlobList{"**/*.go"}, Mode: modeCompact},
+		{Name: "git", Description: "Git"},
+	}
+	full := "# Title\n\nBackground.\n\nAlways rebase.\n"
+	contents := sameContents(selected, []string{full, full, full})
+
+	// when
+	expanded, result := applyRenderModes(selected, groups, contents)
+
+	// then
+	var names []string
+	for _, group := range expanded {
+		names = append(names, group.Name)
+	}
+	if strings.Join(names, ",") != "yaml,yaml-checklist,go,git" {
+		t.Fatalf("applyRenderModes() groups = %v", names)
+	}
+	if len(expanded[1].Globs) > 0 {
+		t.Errorf("checklist should always apply, got globs %v", expanded[1].Globs)
+	}
+	claude := result["claude"]
+	if claude[0] != full || claude[3] != full {
+		t.Errorf("full and default groups should keep their content, got %q and %q", claude[0], claude[3])
+	}
+	if claude[1] != "# YAML (checklist)\n\n- Always rebase.\n\nComplete rules, with examples and rationale: @claude/rules/yaml.md.\n" {
+		t.Errorf("unexpected checklist:\n%s", claude[1])
+	}
+	if claude[2] != "# Go\n\n- Always rebase.\n" {
+		t.Errorf("unexpected compact content:\n%s", claude[2])
+	}
+}
+
+func TestChecklistGolden(t *testing.T) {
+	repoRoot := filepath.Join("..", "..", "..")
+	groups, err := ruleGroups()
+	if err != nil {
+		t.Fatalf("ruleGroups() error: %v", err)
+	}
+	resolver := newLinkResolver(groups, defaultWikiURL)
+
+	for _, name := range []string{"yaml", "git-flow", "testing"} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			var group RuleGroup
+			for _, g := range groups {
+				if g.Name == name {
+					group = g
+				}
+			}
+			merged, missing, err := processGroup(repoRoot, group, []Target{claudeTarget{}}, resolver, newHTMLSanitizer(defaultAllowedHTML))
+			if err != nil || len(missing) > 0 {
+				t.Fatalf("processGroup() error = %v, missing = %v", err, missing)
+			}
+
+			// when
+			result := compactContent(group, merged["claude"])
+
+			// then
+			golden := filepath.Join("testdata", "checklist", name+".md")
+			if *updateGolden {
+				writeTestFile(t, ".", golden, result)
+			}
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("reading golden file (run with -update to create it): %v", err)
+			}
+			if result != string(expected) {
+				t.Errorf("checklist of %s differs from %s (run with -update after reviewing the change):\n%s", name, golden, result)
+			}
+		})
+	}
+}
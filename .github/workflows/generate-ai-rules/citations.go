@@ -0,0 +1,147 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	east "github.com/yuin/goldmark/extension/ast"
+)
+
+// Citation modes of a rule group (RuleGroup.Citations). By default footnotes and the
+// "## References" section are dropped, which keeps the rules short but loses their sources.
+const (
+	citationsInline  = "inline"  // footnote references become the footnote text in parentheses
+	citationsSources = "sources" // external links of footnotes and references are listed once per group
+)
+
+// citationModes are the accepted values of RuleGroup.Citations besides the empty default.
+var citationModes = []string{citationsInline, citationsSources}
+
+// citation is an external source cited by a page, either in a footnote or in its references.
+type citation struct {
+	Title string
+	URL   string
+}
+
+// footnoteVisitor returns the visitor handling footnotes in the given citation mode.
+func footnoteVisitor(mode string) visitor {
+	if mode == citationsInline {
+		return inlineFootnotes
+	}
+	return stripFootnotes
+}
+
+// inlineFootnotes replaces each footnote reference [^N] with the text of its definition
+// in parentheses, so "Clean Architecture[^1]" reads "Clean Architecture ([Clean
+// Architecture Introduction](https://...))". The definitions themselves are removed.
+func inlineFootnotes(node ast.Node, source []byte) []edit {
+	link, ok := node.(*east.FootnoteLink)
+	if !ok {
+		return stripFootnotes(node, source)
+	}
+	text := footnoteText(findFootnote(link), source)
+	if text == "" {
+		return stripFootnotes(node, source)
+	}
+	if start := link.Pos(); start > 0 && source[start-1] != ' ' && source[start-1] != '\t' {
+		text = " (" + text + ")"
+	} else {
+		text = "(" + text + ")"
+	}
+	return []edit{{Start: link.Pos(), Stop: labelEnd(source, link.Pos()) + 1, Text: text}}
+}
+
+// findFootnote returns the definition referenced by link, or nil when there is none.
+func findFootnote(link *east.FootnoteLink) *east.Footnote {
+	var found *east.Footnote
+	_ = ast.Walk(link.OwnerDocument(), func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if footnote, ok := n.(*east.Footnote); ok && entering && footnote.Index == link.Index {
+			found = footnote
+			return ast.WalkStop, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return found
+}
+
+// footnoteText returns the markdown of a footnote definition on a single line.
+func footnoteText(footnote *east.Footnote, source []byte) string {
+	if footnote == nil {
+		return ""
+	}
+	var parts []string
+	for child := footnote.FirstChild(); child != nil; child = child.NextSibling() {
+		if text := blockText(child, source); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// citations returns the external links of the page's footnote definitions and of its
+// "## References" section, in document order.
+func (p page) citations() []citation {
+	var found []citation
+	collect := func(node ast.Node) {
+		_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+			if !entering {
+				return ast.WalkContinue, nil
+			}
+			switch link := n.(type) {
+			case *ast.Link:
+				if isExternal(link.Destination) {
+					found = append(found, citation{Title: strings.TrimSpace(inlineText(link, p.source)), URL: string(link.Destination)})
+				}
+				return ast.WalkSkipChildren, nil
+			case *ast.AutoLink:
+				if url := string(link.URL(p.source)); isExternal([]byte(url)) {
+					found = append(found, citation{URL: url})
+				}
+			}
+			return ast.WalkContinue, nil
+		})
+	}
+
+	inReferences := false
+	for node := p.doc.FirstChild(); node != nil; node = node.NextSibling() {
+		// like stripReferences, everything after the heading belongs to the section
+		if isReferencesHeading(node, p.source) {
+			inReferences = true
+			continue
+		}
+		if inReferences || node.Kind() == east.KindFootnoteList {
+			collect(node)
+		}
+	}
+	return found
+}
+
+// appendCitations adds the citations whose URL is not listed yet.
+func appendCitations(list []citation, more []citation) []citation {
+	for _, c := range more {
+		listed := slices.ContainsFunc(list, func(l citation) bool { return l.URL == c.URL })
+		if !listed {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// formatSources renders the citations of a group as a "## Sources" list.
+// It returns an empty string when there is nothing to cite.
+func formatSources(list []citation) string {
+	if len(list) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("## Sources\n\n")
+	for _, c := range list {
+		if c.Title == "" || c.Title == c.URL {
+			sb.WriteString("- <" + c.URL + ">\n")
+			continue
+		}
+		sb.WriteString("- [" + c.Title + "](" + c.URL + ")\n")
+	}
+	return sb.String()
+}
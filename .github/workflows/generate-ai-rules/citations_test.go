@@ -0,0 +1,133 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInlineFootnotes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "reference replaced with its definition",
+			input:    "Follow Clean Architecture[^1].\n\n[^1]: [Clean Architecture Introduction](https://example.com/clean)\n\nNext.\n",
+			expected: "Follow Clean Architecture ([Clean Architecture Introduction](https://example.com/clean)).\n\nNext.\n",
+		},
+		{
+			name:     "reference after a space",
+			input:    "Use SemVer [^semver].\n\n[^semver]: Semantic Versioning 2.0.0, <https://semver.org/>\n",
+			expected: "Use SemVer (Semantic Versioning 2.0.0, <https://semver.org/>).\n",
+		},
+		{
+			name:     "references in a table header",
+			input:    "| Polluted[^1] | Clean[^1] |\n|---|---|\n| a | b |\n\n[^1]: [Intro](https://example.com/intro)\n",
+			expected: "| Polluted ([Intro](https://example.com/intro)) | Clean ([Intro](https://example.com/intro)) |\n|---|---|\n| a | b |\n",
+		},
+		{
+			name:     "footnote syntax inside code untouched",
+			input:    "Write `[^1]` for a footnote.\n",
+			expected: "Write `[^1]` for a footnote.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			result := parsePage(tt.input).render(footnoteVisitor(citationsInline))
+
+			// then
+			if result != tt.expected {
+				t.Errorf("inline footnotes:\ngot:  %q\nwant: %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFootnoteVisitorStripsByDefault(t *testing.T) {
+	// given
+	input := "Follow Clean Architecture[^1].\n\n[^1]: [Intro](https://example.com/intro)\n"
+
+	// when
+	result := parsePage(input).render(footnoteVisitor(""))
+
+	// then
+	if result != "Follow Clean Architecture.\n" {
+		t.Errorf("default citation mode should drop footnotes, got %q", result)
+	}
+}
+
+func TestPageCitations(t *testing.T) {
+	// given
+	input := "# Title\n\nKeep a changelog[^1], see [the template](Template.md).\n\n" +
+		"[^1]: [Keep a Changelog](https://keepachangelog.com/en/1.1.0/)\n\n" +
+		"## References\n\n" +
+		"- [Keep a Changelog](https://keepachangelog.com/en/1.1.0/)\n" +
+		"- [Semantic Versioning](https://semver.org/)\n" +
+		"- https://common-changelog.org/\n" +
+		"- [Internal Guide](Guide.md)\n"
+
+	// when
+	cited := appendCitations(nil, parsePage(input).citations())
+
+	// then
+	expected := []citation{
+		{Title: "Keep a Changelog", URL: "https://keepachangelog.com/en/1.1.0/"},
+		{Title: "Semantic Versioning", URL: "https://semver.org/"},
+		{URL: "https://common-changelog.org/"},
+	}
+	if len(cited) != len(expected) {
+		t.Fatalf("citations() = %+v, want %+v", cited, expected)
+	}
+	for i := range expected {
+		if cited[i] != expected[i] {
+			t.Errorf("citation %d = %+v, want %+v", i, cited[i], expected[i])
+		}
+	}
+}
+
+func TestFormatSources(t *testing.T) {
+	// given
+	cited := []citation{
+		{Title: "Semantic Versioning", URL: "https://semver.org/"},
+		{URL: "https://common-changelog.org/"},
+	}
+
+	// when
+	result := formatSources(cited)
+
+	// then
+	expected := "## Sources\n\n- [Semantic Versioning](https://semver.org/)\n- <https://common-changelog.org/>\n"
+	if result != expected {
+		t.Errorf("formatSources():\ngot:  %q\nwant: %q", result, expected)
+	}
+	if formatSources(nil) != "" {
+		t.Error("formatSources() should be empty without citations")
+	}
+}
+
+func TestProcessGroupListsSources(t *testing.T) {
+	// given
+	tmpDir := t.TempDir()
+	writeTestFile(t, tmpDir, "a.md", "# A\n\nUse SemVer[^1].\n\n[^1]: [Semantic Versioning](https://semver.org/)\n")
+	writeTestFile(t, tmpDir, "b.md", "# B\n\nRule.\n\n## References\n\n- [Semantic Versioning](https://semver.org/)\n- [Keep a Changelog](https://keepachangelog.com/)\n")
+	group := RuleGroup{Name: "docs", Description: "Docs", Sources: []string{"a.md", "b.md"}, Citations: citationsSources}
+
+	// when
+	merged, _, err := processGroup(tmpDir, group, []Target{claudeTarget{}}, newLinkResolver(nil, ""), newHTMLSanitizer(defaultAllowedHTML))
+
+	// then
+	if err != nil {
+		t.Fatalf("processGroup() error: %v", err)
+	}
+	result := merged["claude"]
+	if strings.Contains(result, "[^1]") || strings.Contains(result, "## References") {
+		t.Errorf("footnotes and references should be replaced by the sources list, got:\n%s", result)
+	}
+	sources := "## Sources\n\n- [Semantic Versioning](https://semver.org/)\n- [Keep a Changelog](https://keepachangelog.com/)\n"
+	if !strings.HasSuffix(result, "\n\n"+sources) {
+		t.Errorf("merged content should end with the deduplicated sources, got:\n%s", result)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+const codexMaxSize = 32 * 1024 // 32 KiB
+
+// codexReservedDirectories are the codex/ subdirectories holding the linked fragments and
+// the command rules, so no group glob may be rooted in them and nest its AGENTS.md there.
+var codexReservedDirectories = []string{"agents", "rules"}
+
+// codexSeparator separates rule groups inside a Codex AGENTS.md file.
+const codexSeparator = "\n---\n\n"
+
+// renderCodex renders the Codex AGENTS.md files within the codexMaxSize budget.
+// The root codex/AGENTS.md holds the always-apply groups, highest priority first.
+// Language groups (those with globs) go to a nested codex/<dir>/AGENTS.md when their
+// glob is rooted in a directory, or to a linked fragment under codex/agents/ otherwise,
+// like the agent-requested and manual groups.
+// Always-apply groups that do not fit the remaining budget are linked the same way,
+// so lower-priority groups are the ones that leave the root file.
+func renderCodex(groups []RuleGroup, contents []string) ([]renderedFile, error) {
+	var always, linked []int
+	nested := make(map[string][]int)
+	for _, i := range priorityOrder(groups) {
+		if contents[i] == "" {
+			continue
+		}
+		switch dir := groups[i].Globs.directory(); {
+		case groups[i].onDemand():
+			linked = append(linked, i)
+		case len(groups[i].Globs) == 0:
+			always = append(always, i)
+		case dir != "":
+			nested[dir] = append(nested[dir], i)
+		default:
+			linked = append(linked, i)
+		}
+	}
+
+	// reserve room for the worst-case index, where every always-apply group is linked too,
+	// and for the provenance block listing the sources of every always-apply group
+	budget := codexMaxSize - len(formatCodexIndex(groups, append(append([]int{}, linked...), always...))) -
+		provenanceSize(groupSources(groups, always))
+	var inline []string
+	var inlined []int
+	var size int
+	for _, i := range always {
+		extra := len(contents[i])
+		if len(inline) > 0 {
+			extra += len(codexSeparator)
+		}
+		if size+extra > budget {
+			logger.WithFields(logger.Fields{
+				"group":       groups[i].Name,
+				"priority":    groups[i].Priority,
+				"size_bytes":  len(contents[i]),
+				"limit_bytes": codexMaxSize,
+			}).Warn("moved always-apply group out of AGENTS.md to fit the Codex limit")
+			linked = append(linked, i)
+			continue
+		}
+		inline = append(inline, contents[i])
+		inlined = append(inlined, i)
+		size += extra
+	}
+
+	body := strings.Join(inline, codexSeparator)
+	if index := formatCodexIndex(groups, linked); index != "" {
+		if body != "" {
+			body += codexSeparator
+		}
+		body += index
+	}
+
+	files := []renderedFile{{Target: "codex", Path: "codex/AGENTS.md", Body: body, Sources: groupSources(groups, inlined)}}
+	for _, i := range linked {
+		files = append(files, renderedFile{
+			Target:  "codex",
+			Path:    codexFragmentPath(groups[i]),
+			Body:    formatGlobNotice(groups[i].Globs) + contents[i],
+			Sources: groups[i].Sources,
+		})
+	}
+
+	dirs := make([]string, 0, len(nested))
+	for dir := range nested {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		var parts []string
+		for _, i := range nested[dir] {
+			parts = append(parts, contents[i])
+		}
+		files = append(files, renderedFile{
+			Target:  "codex",
+			Path:    path.Join("codex", dir, "AGENTS.md"),
+			Body:    strings.Join(parts, codexSeparator),
+			Sources: groupSources(groups, nested[dir]),
+		})
+	}
+
+	// linked fragments are read on demand, so only AGENTS.md files are held to the limit,
+	// counting the provenance block they get once rendered
+	var errs []error
+	for _, file := range files {
+		size := len(file.Body) + provenanceSize(file.Sources)
+		if path.Base(file.Path) != "AGENTS.md" || size <= codexMaxSize {
+			continue
+		}
+		logger.WithFields(logger.Fields{
+			"path":        file.Path,
+			"size_bytes":  size,
+			"limit_bytes": codexMaxSize,
+		}).Warn("AGENTS.md size exceeds Codex limit")
+		errs = append(errs, fmt.Errorf("%w: %s is %d bytes (limit %d)", errBudgetExceeded, file.Path, size, codexMaxSize))
+	}
+	return files, errors.Join(errs...)
+}
+
+// formatCodexIndex lists the linked fragments Codex must read on demand.
+// It returns an empty string when nothing is linked.
+func formatCodexIndex(groups []RuleGroup, linked []int) string {
+	return formatRuleIndex(groups, linked, func(group RuleGroup) string {
+		return strings.TrimPrefix(codexFragmentPath(group), "codex/")
+	})
+}
+
+// codexFragmentPath returns the path of a group's linked Codex fragment.
+func codexFragmentPath(group RuleGroup) string {
+	return "codex/agents/" + group.Name + ".md"
+}
+
+// priorityOrder returns group indexes sorted by descending priority, keeping the
+// declaration order for groups with the same priority.
+func priorityOrder(groups []RuleGroup) []int {
+	order := make([]int, len(groups))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return groups[order[a]].Priority > groups[order[b]].Priority
+	})
+	return order
+}
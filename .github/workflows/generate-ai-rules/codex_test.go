@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderCodex(t *testing.T) {
+	// given
+	groups := []RuleGroup{
+		{Name: "bulk-operations", Description: "Bulk operations"},
+		{Name: "golang", Description: "Go standards", Globs: GlobList{"**/*.go"}},
+		{Name: "code-style", Description: "Code style", Priority: 3},
+		{Name: "docs", Description: "Docs", Globs: GlobList{"docs/**/*.md"}},
+		{Name: "empty", Description: "Empty group"},
+		{Name: "release", Description: "Release checklist", Activation: activationManual},
+	}
+	contents := []string{"# Bulk\n", "# Go\n", "# Code Style\n", "# Docs\n", "", "# Release\n"}
+
+	// when
+	files, err := renderCodex(groups, contents)
+
+	// then
+	if err != nil {
+		t.Fatalf("renderCodex() error: %v", err)
+	}
+	bodies := make(map[string]string)
+	for _, file := range files {
+		bodies[file.Path] = file.Body
+	}
+	expectedRoot := "# Code Style\n\n---\n\n# Bulk\n\n---\n\n## Additional Rules\n\n" +
+		"Read the linked file before working on the matching files or topics:\n\n" +
+		"- `agents/golang.md`: Go standards (files matching `**/*.go`)\n" +
+		"- `agents/release.md`: Release checklist (only when the user asks for it)\n"
+	if bodies["codex/AGENTS.md"] != expectedRoot {
+		t.Errorf("codex/AGENTS.md\n  got:  %q\n  want: %q", bodies["codex/AGENTS.md"], expectedRoot)
+	}
+	if bodies["codex/agents/golang.md"] != "> Applies to files matching `**/*.go`.\n\n# Go\n" {
+		t.Errorf("codex/agents/golang.md = %q", bodies["codex/agents/golang.md"])
+	}
+	if bodies["codex/docs/AGENTS.md"] != "# Docs\n" {
+		t.Errorf("codex/docs/AGENTS.md = %q", bodies["codex/docs/AGENTS.md"])
+	}
+	if bodies["codex/agents/release.md"] != "# Release\n" {
+		t.Errorf("codex/agents/release.md = %q", bodies["codex/agents/release.md"])
+	}
+	if len(files) != 4 {
+		t.Errorf("renderCodex() returned %d files, want 4", len(files))
+	}
+}
+
+func TestRenderCodexMovesLowPriorityGroupsOverBudget(t *testing.T) {
+	// given
+	large := "# Large\n\n" + strings.Repeat("x", codexMaxSize/2) + "\n"
+	groups := []RuleGroup{
+		{Name: "low", Description: "Low priority", Priority: 1},
+		{Name: "high", Description: "High priority", Priority: 5},
+		{Name: "medium", Description: "Medium priority", Priority: 3},
+	}
+	contents := []string{large, large, large}
+
+	// when
+	files, err := renderCodex(groups, contents)
+
+	// then
+	if err != nil {
+		t.Fatalf("renderCodex() error: %v", err)
+	}
+	root := files[0]
+	if len(root.Body) > codexMaxSize {
+		t.Errorf("root AGENTS.md is %d bytes, limit is %d", len(root.Body), codexMaxSize)
+	}
+	if strings.Contains(root.Body, "agents/high.md") {
+		t.Error("highest-priority group should stay in the root AGENTS.md")
+	}
+	for _, name := range []string{"medium", "low"} {
+		if !strings.Contains(root.Body, "agents/"+name+".md") {
+			t.Errorf("root AGENTS.md should link the %s group", name)
+		}
+	}
+}
+
+func TestRenderCodexReportsUnmetBudget(t *testing.T) {
+	// given
+	groups := []RuleGroup{{Name: "docs", Description: "Docs", Globs: GlobList{"docs/**/*.md"}}}
+	contents := []string{strings.Repeat("x", codexMaxSize+1)}
+
+	// when
+	_, err := renderCodex(groups, contents)
+
+	// then
+	if !errors.Is(err, errBudgetExceeded) {
+		t.Errorf("renderCodex() error = %v, want errBudgetExceeded", err)
+	}
+}
+
+func TestWriteCodex(t *testing.T) {
+	// given
+	tmpDir := t.TempDir()
+	groups := []RuleGroup{
+		{Name: "code-style", Description: "Code style"},
+		{Name: "golang", Description: "Go standards", Globs: GlobList{"**/*.go"}},
+	}
+	contents := []string{
+		"# Code Style\n\nNaming conventions.\n",
+		"# Go\n\nUse gofmt.\n",
+	}
+
+	// when
+	files, err := renderCodex(groups, contents)
+	if err != nil {
+		t.Fatalf("renderCodex() error: %v", err)
+	}
+	for _, file := range files {
+		if err := writeRenderedFile(tmpDir, file); err != nil {
+			t.Fatalf("writeRenderedFile() error: %v", err)
+		}
+	}
+
+	// then
+	data, err := os.ReadFile(filepath.Join(tmpDir, "codex", "AGENTS.md"))
+	if err != nil {
+		t.Fatalf("reading AGENTS.md: %v", err)
+	}
+	if !strings.Contains(string(data), "# Code Style") {
+		t.Error("AGENTS.md should contain code-style content")
+	}
+	if !strings.Contains(string(data), "agents/golang.md") {
+		t.Error("AGENTS.md should link the golang fragment")
+	}
+	assertFileContains(t, filepath.Join(tmpDir, "codex", "agents", "golang.md"), "# Go")
+}
+
+func TestPriorityOrder(t *testing.T) {
+	// given
+	groups := []RuleGroup{
+		{Name: "a"},
+		{Name: "b", Priority: 2},
+		{Name: "c"},
+		{Name: "d", Priority: 2},
+	}
+
+	// when
+	order := priorityOrder(groups)
+
+	// then
+	var names []string
+	for _, i := range order {
+		names = append(names, groups[i].Name)
+	}
+	if strings.Join(names, ",") != "b,d,a,c" {
+		t.Errorf("priorityOrder() = %v, want [b d a c]", names)
+	}
+}
@@ -1,155 +1,34 @@
 package main
 
+import (
+	_ "embed"
+)
+
 // RuleGroup defines a mapping from source markdown files to a single rule output.
+// The yaml tags define the schema of the rule group manifest (see manifest.go).
 type RuleGroup struct {
-	Name        string   // output filename (without extension)
-	Description string   // human-readable description for Cursor frontmatter
-	Sources     []string // relative paths from repo root, in concatenation order
-	Globs       string   // file glob for language targeting; empty for always-apply
+	Name        string   `yaml:"name"`                   // output filename (without extension)
+	Description string   `yaml:"description"`            // human-readable description for Cursor frontmatter
+	Sources     []string `yaml:"sources"`                // relative paths from repo root, in concatenation order
+	Globs       GlobList `yaml:"globs,omitempty"`        // file globs for language targeting, "!" to exclude; empty for always-apply
+	Activation  string   `yaml:"activation,omitempty"`   // "always", "glob", "agent-requested", or "manual"; empty derives it from the globs
+	Priority    int      `yaml:"priority,omitempty"`     // higher priorities come first and keep their place in size-limited outputs
+	TokenBudget int      `yaml:"token_budget,omitempty"` // maximum estimated tokens of the merged content; zero means unlimited
+	Outline     bool     `yaml:"outline,omitempty"`      // prepend a generated outline of the merged pages
+	Citations   string   `yaml:"citations,omitempty"`    // "inline" or "sources" to keep footnotes and references; empty drops them
+	Mode        string   `yaml:"mode,omitempty"`         // "full" (default), "compact" for a condensed checklist, or "both"
 }
 
-// ruleGroups returns the complete list of rule group definitions.
-// Language sub-pages follow the order defined in Code-Style/Language-Guide-Template.md:
-// index → Conventions → Formatting → Type System → Logging → Testing → Project Structure.
-func ruleGroups() []RuleGroup {
-	return []RuleGroup{
-		// Language-specific rules
-		{
-			Name:        "golang",
-			Description: "Go language coding standards and conventions",
-			Sources: []string{
-				"Code-Style/GoLang.md",
-				"Code-Style/GoLang/GoLang-Conventions.md",
-				"Code-Style/GoLang/GoLang-Formatting-and-Linting.md",
-				"Code-Style/GoLang/GoLang-Type-System.md",
-				"Code-Style/GoLang/GoLang-Logging.md",
-				"Code-Style/GoLang/GoLang-Testing.md",
-				"Code-Style/GoLang/GoLang-Project-Structure.md",
-			},
-			Globs: "**/*.go",
-		},
-		{
-			Name:        "python",
-			Description: "Python language coding standards and conventions",
-			Sources: []string{
-				"Code-Style/Python.md",
-				"Code-Style/Python/Python-Conventions.md",
-				"Code-Style/Python/Python-Formatting-and-Linting.md",
-				"Code-Style/Python/Python-Type-System.md",
-				"Code-Style/Python/Python-Logging.md",
-				"Code-Style/Python/Python-Testing.md",
-				"Code-Style/Python/Python-Project-Structure.md",
-			},
-			Globs: "**/*.py",
-		},
-		{
-			Name:        "java",
-			Description: "Java language coding standards and conventions",
-			Sources: []string{
-				"Code-Style/Java.md",
-				"Code-Style/Java/Java-Conventions.md",
-				"Code-Style/Java/Java-Formatting-and-Linting.md",
-				"Code-Style/Java/Java-Type-System.md",
-				"Code-Style/Java/Java-Logging.md",
-				"Code-Style/Java/Java-Testing.md",
-				"Code-Style/Java/Java-Project-Structure.md",
-			},
-			Globs: "**/*.java",
-		},
-		{
-			Name:        "javascript",
-			Description: "JavaScript and TypeScript coding standards and conventions",
-			Sources: []string{
-				"Code-Style/JavaScript.md",
-				"Code-Style/JavaScript/JavaScript-Testing.md",
-			},
-			Globs: "**/*.{js,jsx,ts,tsx}",
-		},
-		{
-			Name:        "yaml",
-			Description: "YAML coding standards and conventions",
-			Sources: []string{
-				"Code-Style/YAML.md",
-			},
-			Globs: "**/*.{yml,yaml}",
-		},
-		// Cross-cutting concerns
-		{
-			Name:        "code-style",
-			Description: "General code style and naming conventions",
-			Sources: []string{
-				"Code-Style.md",
-			},
-		},
-		{
-			Name:        "git-flow",
-			Description: "Git workflow, branching, and commit conventions",
-			Sources: []string{
-				"Life-Cycle/Git-Flow.md",
-				"Life-Cycle/Git-Flow/Merge-Guide.md",
-			},
-		},
-		{
-			Name:        "testing",
-			Description: "Testing standards and patterns",
-			Sources: []string{
-				"Life-Cycle/Tests.md",
-			},
-		},
-		{
-			Name:        "architecture",
-			Description: "Architecture principles and design patterns",
-			Sources: []string{
-				"Life-Cycle/Architecture.md",
-				"Life-Cycle/Architecture/Backend-Design.md",
-				"Life-Cycle/Architecture/Frontend-Design.md",
-			},
-		},
-		{
-			Name:        "security",
-			Description: "Security practices and SAST pipeline",
-			Sources: []string{
-				"Life-Cycle/Security.md",
-			},
-		},
-		{
-			Name:        "ci-cd",
-			Description: "CI/CD pipeline standards",
-			Sources: []string{
-				"Life-Cycle/CI-&-CD.md",
-			},
-		},
-		{
-			Name:        "documentation",
-			Description: "Documentation and change control standards",
-			Sources: []string{
-				"Life-Cycle/Documentation-&-Change-Control.md",
-				"Life-Cycle/Documentation-&-Change-Control/README-Template.md",
-				"Life-Cycle/Documentation-&-Change-Control/CONTRIBUTING-Template.md",
-			},
-		},
-		{
-			Name:        "markdown-formatting",
-			Description: "Markdown formatting rules for changelogs and documentation",
-			Sources: []string{
-				"Life-Cycle/Documentation-&-Change-Control/CHANGELOG-Formatting.md",
-			},
-			Globs: "**/*.md",
-		},
-		{
-			Name:        "design-patterns",
-			Description: "Design patterns and coding techniques",
-			Sources: []string{
-				"Cookbooks/Mapper-Design-Pattern.md",
-				"Cookbooks/Forking-Technique.md",
-			},
-		},
-		{
-			Name:        "bulk-operations",
-			Description: "Bulk operations across multiple repositories",
-			Sources: []string{
-				"Cookbooks/Bulk-Operations.md",
-			},
-		},
-	}
+// embeddedManifest is rule-groups.yaml, built into the generator so that it runs without the
+// -config flag.
+//
+//go:embed rule-groups.yaml
+var embeddedManifest []byte
+
+// ruleGroups returns the rule groups declared in the embedded manifest, the default when no
+// manifest is passed through the -config flag. Multi-page language guides are not listed
+// there: they are built from the Code-Style/Language-Guide-Template.md layout by
+// discoverLanguageGroups.
+func ruleGroups() ([]RuleGroup, error) {
+	return decodeManifest(embeddedManifest, "rule-groups.yaml")
 }
@@ -0,0 +1,49 @@
+package main
+
+// continueTarget emits one Continue rule file per group.
+type continueTarget struct{}
+
+func (continueTarget) Name() string    { return "continue" }
+func (continueTarget) Paths() []string { return []string{"continue/rules"} }
+
+func (continueTarget) Reference(group RuleGroup) string { return "see the " + group.Name + " rule" }
+
+func (continueTarget) RenderGroup(group RuleGroup, content string) []renderedFile {
+	return []renderedFile{renderContinue(group, content)}
+}
+
+func (continueTarget) RenderAggregate([]RuleGroup, []string) ([]renderedFile, error) { return nil, nil }
+
+// renderContinue renders a rule file in Continue format at continue/rules/<name>.md.
+func renderContinue(group RuleGroup, content string) renderedFile {
+	return renderedFile{
+		Target: "continue",
+		Path:   "continue/rules/" + group.Name + ".md",
+		Body:   formatContinueFrontmatter(group.Name, group.Description, group.Globs, group.activation()) + content,
+	}
+}
+
+// continueFrontmatter is the front matter of a Continue rule file.
+type continueFrontmatter struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description,omitempty"`
+	Globs       GlobList `yaml:"globs,omitempty"`
+	AlwaysApply bool     `yaml:"alwaysApply"`
+}
+
+// formatContinueFrontmatter returns the frontmatter string for a Continue rule file.
+// A single glob is written as a string and several as a list, both of which Continue reads.
+// Continue picks a rule that does not always apply by its description, so manual rules
+// are written without one.
+func formatContinueFrontmatter(name string, description string, globs GlobList, activation string) string {
+	frontmatter := continueFrontmatter{Name: name, Description: description}
+	switch activation {
+	case activationGlob:
+		frontmatter.Globs = globs.translate(continueGlobs)
+	case activationManual:
+		frontmatter.Description = ""
+	case activationAlways:
+		frontmatter.AlwaysApply = true
+	}
+	return formatFrontmatter(frontmatter)
+}
@@ -0,0 +1,78 @@
+package main
+
+import "testing"
+
+func TestFormatContinueFrontmatter(t *testing.T) {
+	tests := []struct {
+		name        string
+		ruleName    string
+		description string
+		globs       GlobList
+		activation  string
+		expected    string
+	}{
+		{
+			name:        "language-specific with globs",
+			ruleName:    "golang",
+			description: "Go language coding standards",
+			globs:       GlobList{"**/*.go"},
+			activation:  activationGlob,
+			expected:    "---\nname: 'golang'\ndescription: 'Go language coding standards'\nglobs: '**/*.go'\nalwaysApply: false\n---\n\n",
+		},
+		{
+			name:        "cross-cutting without globs",
+			ruleName:    "code-style",
+			description: "General code style conventions",
+			globs:       nil,
+			activation:  activationAlways,
+			expected:    "---\nname: 'code-style'\ndescription: 'General code style conventions'\nalwaysApply: true\n---\n\n",
+		},
+		{
+			name:        "agent-requested by description",
+			ruleName:    "bulk-operations",
+			description: "Bulk operations",
+			activation:  activationAgentRequested,
+			expected:    "---\nname: 'bulk-operations'\ndescription: 'Bulk operations'\nalwaysApply: false\n---\n\n",
+		},
+		{
+			name:        "manual without a description",
+			ruleName:    "release",
+			description: "Release checklist",
+			activation:  activationManual,
+			expected:    "---\nname: 'release'\nalwaysApply: false\n---\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			ruleName, description, globs, activation := tt.ruleName, tt.description, tt.globs, tt.activation
+
+			// when
+			result := formatContinueFrontmatter(ruleName, description, globs, activation)
+
+			// then
+			if result != tt.expected {
+				t.Errorf("formatContinueFrontmatter(%q, %q, %q, %q)\n  got:  %q\n  want: %q", ruleName, description, globs, activation, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderContinue(t *testing.T) {
+	// given
+	group := RuleGroup{Name: "golang", Description: "Go standards", Globs: GlobList{"**/*.go"}}
+	content := "# Go\n"
+
+	// when
+	file := renderContinue(group, content)
+
+	// then
+	if file.Path != "continue/rules/golang.md" {
+		t.Errorf("path = %q, want continue/rules/golang.md", file.Path)
+	}
+	expected := formatContinueFrontmatter("golang", "Go standards", GlobList{"**/*.go"}, activationGlob) + content
+	if file.Body != expected {
+		t.Errorf("body\n  got:  %q\n  want: %q", file.Body, expected)
+	}
+}
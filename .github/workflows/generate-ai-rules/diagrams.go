@@ -0,0 +1,76 @@
+package main
+
+import (
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// diagramSidecars inlines the text sidecars of the internal images of a page. Assistants
+// cannot see images, so stripImages removes them; a diagram worth keeping gets a sidecar
+// next to its image, either a markdown description named after the image ("flow.png.md")
+// or a Mermaid source named after the image without its extension ("flow.mmd"), which is
+// inlined as a mermaid code block. The wiki shows the image itself and never the sidecar.
+type diagramSidecars struct {
+	sourceDir string
+	pagePath  string   // page path relative to sourceDir
+	missing   []string // images referenced without a sidecar, relative to sourceDir
+}
+
+// newDiagramSidecars returns the sidecar visitor state of the page at pagePath.
+func newDiagramSidecars(sourceDir, pagePath string) *diagramSidecars {
+	return &diagramSidecars{sourceDir: sourceDir, pagePath: pagePath}
+}
+
+// visit inserts the sidecar of an internal image after the top-level block holding it,
+// so that the sidecar replaces a standalone image and follows a table or list with images.
+// Images without a sidecar are recorded in missing.
+func (d *diagramSidecars) visit(node ast.Node, source []byte) []edit {
+	image, ok := node.(*ast.Image)
+	if !ok || isExternal(image.Destination) {
+		return nil
+	}
+	imagePath := d.resolve(string(image.Destination))
+	sidecar, ok := readSidecar(d.sourceDir, imagePath)
+	if !ok {
+		if !slices.Contains(d.missing, imagePath) {
+			d.missing = append(d.missing, imagePath)
+		}
+		return nil
+	}
+
+	block := node
+	for block.Parent() != nil && block.Parent().Kind() != ast.KindDocument {
+		block = block.Parent()
+	}
+	end := blockEnd(source, block)
+	return []edit{{Start: end, Stop: end, Text: "\n\n" + sidecar}}
+}
+
+// resolve returns the path of an image destination relative to sourceDir.
+func (d *diagramSidecars) resolve(destination string) string {
+	destination, _, _ = strings.Cut(destination, "#")
+	destination, _, _ = strings.Cut(destination, "?")
+	if unescaped, err := url.PathUnescape(destination); err == nil {
+		destination = unescaped
+	}
+	return path.Clean(path.Join(path.Dir(d.pagePath), destination))
+}
+
+// readSidecar returns the sidecar of the image at imagePath, preferring a markdown
+// description over a Mermaid source.
+func readSidecar(sourceDir, imagePath string) (string, bool) {
+	if data, err := os.ReadFile(filepath.Join(sourceDir, filepath.FromSlash(imagePath+".md"))); err == nil {
+		return strings.TrimSpace(string(data)), true
+	}
+	mermaid := strings.TrimSuffix(imagePath, path.Ext(imagePath)) + ".mmd"
+	if data, err := os.ReadFile(filepath.Join(sourceDir, filepath.FromSlash(mermaid))); err == nil {
+		return "```mermaid\n" + strings.TrimSpace(string(data)) + "\n```", true
+	}
+	return "", false
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiagramSidecars(t *testing.T) {
+	// given
+	tmpDir := t.TempDir()
+	writeTestFile(t, tmpDir, "Arch/.assets/layers.png.md", "Presentation depends on Domain.\n")
+	writeTestFile(t, tmpDir, "Arch/.assets/flow.mmd", "flowchart LR\n  A --> B\n")
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		missing  []string
+	}{
+		{
+			name:     "markdown sidecar replaces a standalone image",
+			input:    "# Layers\n\n![](.assets/layers.png)\n\nNext.\n",
+			expected: "# Layers\n\nPresentation depends on Domain.\n\nNext.\n",
+		},
+		{
+			name:     "mermaid sidecar inlined as a code block",
+			input:    "Flow:\n\n![Flow](.assets/flow.png)\n",
+			expected: "Flow:\n\n```mermaid\nflowchart LR\n  A --> B\n```\n",
+		},
+		{
+			name:     "sidecar follows a table of images",
+			input:    "| Before | After |\n|---|---|\n| ![](.assets/layers.png) | ![](.assets/flow.png) |\n\nNext.\n",
+			expected: "| Before | After |\n|---|---|\n\nPresentation depends on Domain.\n\n```mermaid\nflowchart LR\n  A --> B\n```\n\nNext.\n",
+		},
+		{
+			name:     "image without sidecar removed and reported",
+			input:    "Text.\n\n![](.assets/other.png)\n\n![](../.assets/shared.svg)\n",
+			expected: "Text.\n",
+			missing:  []string{"Arch/.assets/other.png", ".assets/shared.svg"},
+		},
+		{
+			name:     "external image kept without a sidecar",
+			input:    "![Badge](https://img.shields.io/badge/go-blue)\n",
+			expected: "![Badge](https://img.shields.io/badge/go-blue)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			sidecars := newDiagramSidecars(tmpDir, "Arch/Page.md")
+			result := parsePage(tt.input, sidecars.visit).render()
+
+			// then
+			if result != tt.expected {
+				t.Errorf("rendered page:\ngot:  %q\nwant: %q", result, tt.expected)
+			}
+			if strings.Join(sidecars.missing, ",") != strings.Join(tt.missing, ",") {
+				t.Errorf("missing = %v, want %v", sidecars.missing, tt.missing)
+			}
+		})
+	}
+}
+
+func TestProcessGroupInlinesDiagramSidecars(t *testing.T) {
+	// given
+	tmpDir := t.TempDir()
+	writeTestFile(t, tmpDir, "Design.md", "# Design\n\n![](.assets/flow.png)\n")
+	writeTestFile(t, tmpDir, ".assets/flow.png.md", "Requests go from controllers to repositories.\n")
+	group := RuleGroup{Name: "design", Description: "Design", Sources: []string{"Design.md"}}
+
+	// when
+	merged, _, err := processGroup(tmpDir, group, []Target{claudeTarget{}}, newLinkResolver(nil, ""), newHTMLSanitizer(defaultAllowedHTML))
+
+	// then
+	if err != nil {
+		t.Fatalf("processGroup() error: %v", err)
+	}
+	if result := merged["claude"]; !strings.Contains(result, "## Design\n\nRequests go from controllers to repositories.\n") {
+		t.Errorf("the sidecar should replace the image, got:\n%s", result)
+	}
+}
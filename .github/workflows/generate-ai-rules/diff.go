@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pmezard/go-difflib/difflib"
+	logger "github.com/sirupsen/logrus"
+)
+
+// diffContextLines is the number of unchanged lines printed around each change.
+const diffContextLines = 3
+
+// previewRules prints a unified diff between the rendered files and the tree in baseDir.
+// Files that exist in baseDir under one of the owned paths but are no longer rendered are
+// reported as deletions. It returns whether anything would change.
+func previewRules(w io.Writer, baseDir string, owned []string, files []renderedFile) (bool, error) {
+	rendered := make(map[string]string, len(files))
+	for _, file := range files {
+		rendered[file.Path] = file.Body
+	}
+
+	existing, err := readOwnedFiles(baseDir, owned)
+	if err != nil {
+		return false, err
+	}
+
+	paths := make([]string, 0, len(rendered)+len(existing))
+	for p := range rendered {
+		paths = append(paths, p)
+	}
+	for p := range existing {
+		if _, ok := rendered[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	var changedCount int
+	for _, p := range paths {
+		before, hadBefore := existing[p]
+		after, hasAfter := rendered[p]
+		if hadBefore && hasAfter && before == after {
+			continue
+		}
+		diff, err := unifiedDiff(p, before, hadBefore, after, hasAfter)
+		if err != nil {
+			return false, fmt.Errorf("diffing %s: %w", p, err)
+		}
+		if _, err := io.WriteString(w, diff); err != nil {
+			return false, err
+		}
+		changedCount++
+	}
+
+	logger.WithFields(logger.Fields{
+		"base_dir":      baseDir,
+		"files":         len(files),
+		"changed_files": changedCount,
+	}).Info("compared rendered rules")
+	return changedCount > 0, nil
+}
+
+// readOwnedFiles reads every regular file found at, or below, the owned paths in baseDir.
+// Missing paths are treated as empty, so a first run diffs against nothing.
+func readOwnedFiles(baseDir string, owned []string) (map[string]string, error) {
+	files := make(map[string]string)
+	for _, rel := range owned {
+		root := filepath.Join(baseDir, filepath.FromSlash(rel))
+		err := filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.Type().IsRegular() {
+				return nil
+			}
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			relPath, err := filepath.Rel(baseDir, p)
+			if err != nil {
+				return err
+			}
+			files[filepath.ToSlash(relPath)] = string(data)
+			return nil
+		})
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", rel, err)
+		}
+	}
+	return files, nil
+}
+
+// unifiedDiff formats a git-style unified diff for a single file. Created and deleted
+// files are diffed against /dev/null.
+func unifiedDiff(name string, before string, hadBefore bool, after string, hasAfter bool) (string, error) {
+	fromFile, toFile := "a/"+name, "b/"+name
+	if !hadBefore {
+		fromFile = "/dev/null"
+	}
+	if !hasAfter {
+		toFile = "/dev/null"
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(before),
+		B:        difflib.SplitLines(after),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  diffContextLines,
+	})
+	if err != nil {
+		return "", err
+	}
+	// an empty file that is created or deleted has no hunks; keep the headers visible
+	if diff == "" {
+		diff = fmt.Sprintf("--- %s\n+++ %s\n", fromFile, toFile)
+	}
+	return diff, nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPreviewRules(t *testing.T) {
+	tests := []struct {
+		name          string
+		existing      map[string]string
+		owned         []string
+		files         []renderedFile
+		expectChanged bool
+		expectDiff    []string
+		notExpectDiff []string
+	}{
+		{
+			name:     "identical tree reports no changes",
+			existing: map[string]string{"claude/rules/yaml.md": "# YAML\n"},
+			owned:    []string{"claude/rules"},
+			files: []renderedFile{
+				{Target: "claude", Path: "claude/rules/yaml.md", Body: "# YAML\n"},
+			},
+			expectChanged: false,
+		},
+		{
+			name:     "modified file produces unified diff",
+			existing: map[string]string{"claude/rules/yaml.md": "# YAML\n\nUse single quotes.\n"},
+			owned:    []string{"claude/rules"},
+			files: []renderedFile{
+				{Target: "claude", Path: "claude/rules/yaml.md", Body: "# YAML\n\nUse double quotes.\n"},
+			},
+			expectChanged: true,
+			expectDiff:    []string{"--- a/claude/rules/yaml.md", "+++ b/claude/rules/yaml.md", "-Use single quotes.", "+Use double quotes."},
+		},
+		{
+			name:     "new file diffed against /dev/null",
+			existing: map[string]string{},
+			owned:    []string{"cursor/rules"},
+			files: []renderedFile{
+				{Target: "cursor", Path: "cursor/rules/yaml.mdc", Body: "# YAML\n"},
+			},
+			expectChanged: true,
+			expectDiff:    []string{"--- /dev/null", "+++ b/cursor/rules/yaml.mdc", "+# YAML"},
+		},
+		{
+			name: "stale file in owned directory reported as deleted",
+			existing: map[string]string{
+				"copilot/instructions/yaml.instructions.md": "# YAML\n",
+				"copilot/instructions/old.instructions.md":  "# Old\n",
+				"claude/agents/reviewer.md":                 "# Static asset\n",
+			},
+			owned: []string{"copilot/instructions", "claude/rules"},
+			files: []renderedFile{
+				{Target: "copilot", Path: "copilot/instructions/yaml.instructions.md", Body: "# YAML\n"},
+			},
+			expectChanged: true,
+			expectDiff:    []string{"--- a/copilot/instructions/old.instructions.md", "+++ /dev/null", "-# Old"},
+			notExpectDiff: []string{"reviewer.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			baseDir := t.TempDir()
+			for rel, content := range tt.existing {
+				writeTestFile(t, baseDir, rel, content)
+			}
+			var out strings.Builder
+
+			// when
+			changed, err := previewRules(&out, baseDir, tt.owned, tt.files)
+
+			// then
+			if err != nil {
+				t.Fatalf("previewRules() error: %v", err)
+			}
+			if changed != tt.expectChanged {
+				t.Errorf("previewRules() changed = %v, want %v\n%s", changed, tt.expectChanged, out.String())
+			}
+			for _, substr := range tt.expectDiff {
+				if !strings.Contains(out.String(), substr) {
+					t.Errorf("diff should contain %q, got:\n%s", substr, out.String())
+				}
+			}
+			for _, substr := range tt.notExpectDiff {
+				if strings.Contains(out.String(), substr) {
+					t.Errorf("diff should not contain %q, got:\n%s", substr, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestPreviewRulesDoesNotWrite(t *testing.T) {
+	// given
+	baseDir := t.TempDir()
+	groups := []RuleGroup{{Name: "code-style", Description: "Code style"}}
+	contents := []string{"# Code Style\n"}
+	files, _ := renderAllRules(targets(), groups, sameContents(targets(), contents))
+	var out strings.Builder
+
+	// when
+	changed, err := previewRules(&out, baseDir, targetPaths(targets()), files)
+
+	// then
+	if err != nil {
+		t.Fatalf("previewRules() error: %v", err)
+	}
+	if !changed {
+		t.Error("previewRules() should report changes against an empty tree")
+	}
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		t.Fatalf("reading base dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("previewRules() should not write files, found %d entries in %s", len(entries), filepath.Base(baseDir))
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+// codeStyleDir is the directory holding one index page and one sub-page directory per language.
+const codeStyleDir = "Code-Style"
+
+// languagePageSuffixes lists the sub-pages required by Code-Style/Language-Guide-Template.md,
+// in template order: Conventions → Formatting → Type System → Logging → Testing → Project Structure.
+var languagePageSuffixes = []string{
+	"Conventions",
+	"Formatting-and-Linting",
+	"Type-System",
+	"Logging",
+	"Testing",
+	"Project-Structure",
+}
+
+// languageProfile holds the rule group metadata that cannot be derived from the directory layout.
+type languageProfile struct {
+	Name        string   // output filename (without extension)
+	Description string   // human-readable description for Cursor frontmatter
+	Globs       GlobList // file globs for language targeting
+}
+
+// languageProfiles maps each Code-Style/<Language> directory name to its rule group metadata.
+var languageProfiles = map[string]languageProfile{
+	"GoLang":     {Name: "golang", Description: "Go language coding standards and conventions", Globs: GlobList{"**/*.go"}},
+	"Python":     {Name: "python", Description: "Python language coding standards and conventions", Globs: GlobList{"**/*.py"}},
+	"Java":       {Name: "java", Description: "Java language coding standards and conventions", Globs: GlobList{"**/*.java"}},
+	"JavaScript": {Name: "javascript", Description: "JavaScript and TypeScript coding standards and conventions", Globs: GlobList{"**/*.js", "**/*.jsx", "**/*.ts", "**/*.tsx"}},
+}
+
+// DiscoveryWarning describes a deviation from the language guide template found while scanning Code-Style/.
+type DiscoveryWarning struct {
+	Language string // language directory name, e.g. "JavaScript"
+	Path     string // relative path of the offending (or missing) page
+	Reason   string // short machine-friendly reason
+}
+
+// discoverLanguageGroups builds one RuleGroup per Code-Style/<Language>/ directory, listing the
+// index page and the template sub-pages in template order. Pages that are missing or that fall
+// outside the template are reported as warnings instead of silently changing the output.
+func discoverLanguageGroups(sourceDir string) ([]RuleGroup, []DiscoveryWarning, error) {
+	entries, err := os.ReadDir(filepath.Join(sourceDir, codeStyleDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading %s: %w", codeStyleDir, err)
+	}
+
+	var groups []RuleGroup
+	var warnings []DiscoveryWarning
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		group, groupWarnings, err := discoverLanguage(sourceDir, entry.Name())
+		if err != nil {
+			return nil, nil, err
+		}
+		warnings = append(warnings, groupWarnings...)
+		if len(group.Sources) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups, warnings, nil
+}
+
+// discoverLanguage builds the RuleGroup for a single language directory.
+func discoverLanguage(sourceDir string, language string) (RuleGroup, []DiscoveryWarning, error) {
+	var warnings []DiscoveryWarning
+	profile, ok := languageProfiles[language]
+	if !ok {
+		name := languageGroupName(language)
+		if !groupNameRegex.MatchString(name) {
+			return RuleGroup{}, nil, fmt.Errorf("%s: no group name can be derived from the directory name", path.Join(codeStyleDir, language))
+		}
+		profile = languageProfile{
+			Name:        name,
+			Description: language + " language coding standards and conventions",
+		}
+		warnings = append(warnings, DiscoveryWarning{
+			Language: language,
+			Path:     path.Join(codeStyleDir, language),
+			Reason:   "no glob mapping; rule will always apply",
+		})
+	}
+	group := RuleGroup{
+		Name:        profile.Name,
+		Description: profile.Description,
+		Globs:       profile.Globs,
+		Outline:     true,
+	}
+
+	expected := map[string]bool{}
+	candidates := []string{path.Join(codeStyleDir, language+".md")}
+	for _, suffix := range languagePageSuffixes {
+		page := path.Join(codeStyleDir, language, language+"-"+suffix+".md")
+		candidates = append(candidates, page)
+		expected[path.Base(page)] = true
+	}
+	for _, page := range candidates {
+		if _, err := os.Stat(filepath.Join(sourceDir, page)); err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				return RuleGroup{}, nil, fmt.Errorf("checking %s: %w", page, err)
+			}
+			warnings = append(warnings, DiscoveryWarning{Language: language, Path: page, Reason: "missing template page"})
+			continue
+		}
+		group.Sources = append(group.Sources, page)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(sourceDir, codeStyleDir, language))
+	if err != nil {
+		return RuleGroup{}, nil, fmt.Errorf("reading %s: %w", path.Join(codeStyleDir, language), err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") || expected[entry.Name()] {
+			continue
+		}
+		warnings = append(warnings, DiscoveryWarning{
+			Language: language,
+			Path:     path.Join(codeStyleDir, language, entry.Name()),
+			Reason:   "page outside the template; not included",
+		})
+	}
+	return group, warnings, nil
+}
+
+// languageSymbols spells out the symbols that set language names apart, e.g. C++ and C#.
+var languageSymbols = strings.NewReplacer("+", "plus", "#", "sharp")
+
+// languageGroupName derives a kebab-case group name from a language directory without a
+// profile: symbols are spelled out and every other run of characters that is not a letter
+// or digit becomes a hyphen, e.g. "Objective C" -> "objective-c" and "C++" -> "cplusplus".
+func languageGroupName(language string) string {
+	name := strings.ToLower(languageSymbols.Replace(language))
+	return strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	}), "-")
+}
+
+// mergeGroups places discovered groups before the declared ones. A declared group with the
+// same name as a discovered group wins, so the manifest can still override discovery.
+func mergeGroups(discovered []RuleGroup, declared []RuleGroup) []RuleGroup {
+	names := make(map[string]bool, len(declared))
+	for _, group := range declared {
+		names[group.Name] = true
+	}
+
+	merged := make([]RuleGroup, 0, len(discovered)+len(declared))
+	for _, group := range discovered {
+		if names[group.Name] {
+			logger.WithFields(logger.Fields{
+				"group": group.Name,
+			}).Debug("declared group overrides discovered group")
+			continue
+		}
+		merged = append(merged, group)
+	}
+	return append(merged, declared...)
+}
+
+// logDiscoveryWarnings reports every discovery warning as a structured log entry.
+func logDiscoveryWarnings(warnings []DiscoveryWarning) {
+	for _, w := range warnings {
+		logger.WithFields(logger.Fields{
+			"language": w.Language,
+			"path":     w.Path,
+			"reason":   w.Reason,
+		}).Warn("language guide deviates from template")
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiscoverLanguageGroups(t *testing.T) {
+	// given
+	sourceDir := t.TempDir()
+	writeTestFile(t, sourceDir, "Code-Style.md", "# Code Style\n")
+	writeTestFile(t, sourceDir, "Code-Style/Language-Guide-Template.md", "# Template\n")
+	writeTestFile(t, sourceDir, "Code-Style/YAML.md", "# YAML\n")
+	writeTestFile(t, sourceDir, "Code-Style/GoLang.md", "# Go\n")
+	for _, suffix := range languagePageSuffixes {
+		writeTestFile(t, sourceDir, "Code-Style/GoLang/GoLang-"+suffix+".md", "# Go "+suffix+"\n")
+	}
+	writeTestFile(t, sourceDir, "Code-Style/JavaScript.md", "# JavaScript\n")
+	writeTestFile(t, sourceDir, "Code-Style/JavaScript/JavaScript-Testing.md", "# JS Testing\n")
+	writeTestFile(t, sourceDir, "Code-Style/JavaScript/JavaScript-Tips.md", "# JS Tips\n")
+	writeTestFile(t, sourceDir, "Code-Style/Rust/Rust-Conventions.md", "# Rust Conventions\n")
+
+	// when
+	groups, warnings, err := discoverLanguageGroups(sourceDir)
+
+	// then
+	if err != nil {
+		t.Fatalf("discoverLanguageGroups() error: %v", err)
+	}
+	expectedGroups := []RuleGroup{
+		{
+			Name:        "golang",
+			Description: "Go language coding standards and conventions",
+			Sources: []string{
+				"Code-Style/GoLang.md",
+				"Code-Style/GoLang/GoLang-Conventions.md",
+				"Code-Style/GoLang/GoLang-Formatting-and-Linting.md",
+				"Code-Style/GoLang/GoLang-Type-System.md",
+				"Code-Style/GoLang/GoLang-Logging.md",
+				"Code-Style/GoLang/GoLang-Testing.md",
+				"Code-Style/GoLang/GoLang-Project-Structure.md",
+			},
+			Globs:   GlobList{"**/*.go"},
+			Outline: true,
+		},
+		{
+			Name:        "javascript",
+			Description: "JavaScript and TypeScript coding standards and conventions",
+			Sources: []string{
+				"Code-Style/JavaScript.md",
+				"Code-Style/JavaScript/JavaScript-Testing.md",
+			},
+			Globs:   GlobList{"**/*.js", "**/*.jsx", "**/*.ts", "**/*.tsx"},
+			Outline: true,
+		},
+		{
+			Name:        "rust",
+			Description: "Rust language coding standards and conventions",
+			Sources: []string{
+				"Code-Style/Rust/Rust-Conventions.md",
+			},
+			Outline: true,
+		},
+	}
+	if !reflect.DeepEqual(groups, expectedGroups) {
+		t.Errorf("discoverLanguageGroups() groups\n  got:  %+v\n  want: %+v", groups, expectedGroups)
+	}
+
+	expectedWarnings := []DiscoveryWarning{
+		{Language: "JavaScript", Path: "Code-Style/JavaScript/JavaScript-Conventions.md", Reason: "missing template page"},
+		{Language: "JavaScript", Path: "Code-Style/JavaScript/JavaScript-Formatting-and-Linting.md", Reason: "missing template page"},
+		{Language: "JavaScript", Path: "Code-Style/JavaScript/JavaScript-Type-System.md", Reason: "missing template page"},
+		{Language: "JavaScript", Path: "Code-Style/JavaScript/JavaScript-Logging.md", Reason: "missing template page"},
+		{Language: "JavaScript", Path: "Code-Style/JavaScript/JavaScript-Project-Structure.md", Reason: "missing template page"},
+		{Language: "JavaScript", Path: "Code-Style/JavaScript/JavaScript-Tips.md", Reason: "page outside the template; not included"},
+		{Language: "Rust", Path: "Code-Style/Rust", Reason: "no glob mapping; rule will always apply"},
+		{Language: "Rust", Path: "Code-Style/Rust.md", Reason: "missing template page"},
+		{Language: "Rust", Path: "Code-Style/Rust/Rust-Formatting-and-Linting.md", Reason: "missing template page"},
+		{Language: "Rust", Path: "Code-Style/Rust/Rust-Type-System.md", Reason: "missing template page"},
+		{Language: "Rust", Path: "Code-Style/Rust/Rust-Logging.md", Reason: "missing template page"},
+		{Language: "Rust", Path: "Code-Style/Rust/Rust-Testing.md", Reason: "missing template page"},
+		{Language: "Rust", Path: "Code-Style/Rust/Rust-Project-Structure.md", Reason: "missing template page"},
+	}
+	if !reflect.DeepEqual(warnings, expectedWarnings) {
+		t.Errorf("discoverLanguageGroups() warnings\n  got:  %+v\n  want: %+v", warnings, expectedWarnings)
+	}
+}
+
+func TestDiscoverLanguageGroupsWithoutCodeStyle(t *testing.T) {
+	// given
+	sourceDir := t.TempDir()
+
+	// when
+	groups, warnings, err := discoverLanguageGroups(sourceDir)
+
+	// then
+	if err != nil {
+		t.Fatalf("discoverLanguageGroups() error: %v", err)
+	}
+	if len(groups) != 0 || len(warnings) != 0 {
+		t.Errorf("expected no groups and no warnings, got %d groups and %d warnings", len(groups), len(warnings))
+	}
+}
+
+func TestLanguageGroupName(t *testing.T) {
+	tests := []struct {
+		language string
+		expected string
+	}{
+		{language: "Rust", expected: "rust"},
+		{language: "Objective C", expected: "objective-c"},
+		{language: "C++", expected: "cplusplus"},
+		{language: "C#", expected: "csharp"},
+		{language: "Shell (Bash)", expected: "shell-bash"},
+		{language: "Kotlin_2.0", expected: "kotlin-2-0"},
+		{language: "日本語", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			// when
+			name := languageGroupName(tt.language)
+
+			// then
+			if name != tt.expected {
+				t.Errorf("languageGroupName(%q) = %q, want %q", tt.language, name, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDiscoverLanguageGroupsRejectsUnnamableDirectory(t *testing.T) {
+	// given
+	sourceDir := t.TempDir()
+	writeTestFile(t, sourceDir, "Code-Style/日本語/日本語-Conventions.md", "# Conventions\n")
+
+	// when
+	_, _, err := discoverLanguageGroups(sourceDir)
+
+	// then
+	if err == nil || !strings.Contains(err.Error(), "no group name can be derived") {
+		t.Errorf("discoverLanguageGroups() error = %v, want an error about the group name", err)
+	}
+}
+
+func TestMergeGroups(t *testing.T) {
+	// given
+	discovered := []RuleGroup{
+		{Name: "golang", Description: "discovered Go"},
+		{Name: "python", Description: "discovered Python"},
+	}
+	declared := []RuleGroup{
+		{Name: "yaml", Description: "declared YAML"},
+		{Name: "python", Description: "declared Python"},
+	}
+
+	// when
+	merged := mergeGroups(discovered, declared)
+
+	// then
+	var names, descriptions []string
+	for _, group := range merged {
+		names = append(names, group.Name)
+		descriptions = append(descriptions, group.Description)
+	}
+	expectedNames := []string{"golang", "yaml", "python"}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("mergeGroups() names = %v, want %v", names, expectedNames)
+	}
+	if descriptions[2] != "declared Python" {
+		t.Errorf("declared group should override discovered group, got %q", descriptions[2])
+	}
+}
@@ -9,93 +9,80 @@ import (
 	logger "github.com/sirupsen/logrus"
 )
 
-const codexMaxSize = 32 * 1024 // 32 KiB
+// renderedFile is a generated file held in memory before it is written to disk.
+type renderedFile struct {
+	Target  string   // assistant the file belongs to, e.g. "claude"
+	Path    string   // slash-separated path relative to the output directory
+	Body    string   // complete file content
+	Sources []string // source pages the content came from, listed in its provenance block
+}
 
-// writeClaude writes a rule file in Claude Code format to claude/rules/<name>.md.
-func writeClaude(outputDir string, group RuleGroup, content string) error {
-	dir := filepath.Join(outputDir, "claude", "rules")
+// writeRenderedFile writes a rendered file below outputDir, creating parent directories as needed.
+func writeRenderedFile(outputDir string, file renderedFile) error {
+	path := filepath.Join(outputDir, filepath.FromSlash(file.Path))
+	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("creating directory %s: %w", dir, err)
 	}
-
-	var body string
-	if group.Globs != "" {
-		body = fmt.Sprintf("---\npaths:\n  - \"%s\"\n---\n\n%s", group.Globs, content)
-	} else {
-		body = content
-	}
-
-	path := filepath.Join(dir, group.Name+".md")
-	if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+	if err := os.WriteFile(path, []byte(file.Body), 0644); err != nil {
 		return err
 	}
 	logger.WithFields(logger.Fields{
-		"path":  path,
-		"bytes": len(body),
-	}).Debug("wrote Claude rule file")
+		"target": file.Target,
+		"path":   path,
+		"bytes":  len(file.Body),
+	}).Debug("wrote rule file")
 	return nil
 }
 
-// writeCursor writes a rule file in Cursor format to cursor/rules/<name>.mdc.
-func writeCursor(outputDir string, group RuleGroup, content string) error {
-	dir := filepath.Join(outputDir, "cursor", "rules")
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("creating directory %s: %w", dir, err)
-	}
-
-	var frontmatter string
-	if group.Globs != "" {
-		frontmatter = fmt.Sprintf("---\ndescription: \"%s\"\nglobs: \"%s\"\nalwaysApply: false\n---\n\n",
-			group.Description, group.Globs)
-	} else {
-		frontmatter = fmt.Sprintf("---\ndescription: \"%s\"\nalwaysApply: true\n---\n\n",
-			group.Description)
-	}
-
-	body := frontmatter + content
-	path := filepath.Join(dir, group.Name+".mdc")
-	if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+// removeRenderedFile removes a file that is no longer rendered, along with the directories
+// it leaves empty below outputDir.
+func removeRenderedFile(outputDir string, relPath string) error {
+	path := filepath.Join(outputDir, filepath.FromSlash(relPath))
+	if err := os.Remove(path); err != nil {
 		return err
 	}
 	logger.WithFields(logger.Fields{
-		"path":  path,
-		"bytes": len(body),
-	}).Debug("wrote Cursor rule file")
+		"path": path,
+	}).Debug("removed stale rule file")
+	for dir := filepath.Dir(path); dir != filepath.Clean(outputDir); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break // not empty
+		}
+	}
 	return nil
 }
 
-// writeCodex writes a single AGENTS.md file for Codex by concatenating all rule groups.
-func writeCodex(outputDir string, groups []RuleGroup, contents []string) error {
-	var sb strings.Builder
-	for i := range groups {
-		if i > 0 {
-			sb.WriteString("\n---\n\n")
+// renderClaude renders a rule file in Claude Code format at claude/rules/<name>.md.
+// Claude Code loads every rule up front, so agent-requested and manual groups become
+// skills at claude/skills/<name>/SKILL.md, which are loaded when invoked.
+func renderClaude(group RuleGroup, content string) renderedFile {
+	if group.onDemand() {
+		return renderedFile{
+			Target: "claude",
+			Path:   claudeSkillPath(group),
+			Body:   formatClaudeSkillFrontmatter(group.Name, group.Description, group.activation()) + content,
 		}
-		sb.WriteString(contents[i])
 	}
-
-	body := sb.String()
-	if len(body) > codexMaxSize {
-		logger.WithFields(logger.Fields{
-			"size_bytes":  len(body),
-			"limit_bytes": codexMaxSize,
-		}).Warn("AGENTS.md size exceeds Codex limit")
+	return renderedFile{
+		Target: "claude",
+		Path:   "claude/rules/" + group.Name + ".md",
+		Body:   formatClaudeFrontmatter(group.Globs) + content,
 	}
+}
 
-	dir := filepath.Join(outputDir, "codex")
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("creating directory %s: %w", dir, err)
-	}
+// claudeSkillPath returns the path of the skill an on-demand group becomes for Claude.
+func claudeSkillPath(group RuleGroup) string {
+	return "claude/skills/" + group.Name + "/SKILL.md"
+}
 
-	path := filepath.Join(dir, "AGENTS.md")
-	if err := os.WriteFile(path, []byte(body), 0644); err != nil {
-		return err
+// renderCursor renders a rule file in Cursor format at cursor/rules/<name>.mdc.
+func renderCursor(group RuleGroup, content string) renderedFile {
+	return renderedFile{
+		Target: "cursor",
+		Path:   "cursor/rules/" + group.Name + ".mdc",
+		Body:   formatCursorFrontmatter(group.Description, group.Globs, group.activation()) + content,
 	}
-	logger.WithFields(logger.Fields{
-		"path":  path,
-		"bytes": len(body),
-	}).Debug("wrote Codex AGENTS.md")
-	return nil
 }
 
 // CodexRule represents a single prefix_rule entry for Codex command execution policies.
@@ -150,7 +137,6 @@ func codexRules() []CodexRule {
 func formatCodexRules(rules []CodexRule) string {
 	var sb strings.Builder
 	sb.WriteString("# Codex command execution policies\n")
-	sb.WriteString("# Generated from the development guide — do not edit manually.\n")
 	sb.WriteString("# See: https://developers.openai.com/codex/rules/\n\n")
 
 	for i, rule := range rules {
@@ -175,66 +161,104 @@ func formatStarlarkList(items []string) string {
 	return "[" + strings.Join(quoted, ", ") + "]"
 }
 
-// writeCodexRules writes the Codex command execution policy file to codex/rules/default.rules.
-func writeCodexRules(outputDir string) error {
-	dir := filepath.Join(outputDir, "codex", "rules")
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("creating directory %s: %w", dir, err)
+// renderCodexRules renders the Codex command execution policy file at codex/rules/default.rules.
+func renderCodexRules() renderedFile {
+	return renderedFile{
+		Target: "codex",
+		Path:   "codex/rules/default.rules",
+		Body:   formatCodexRules(codexRules()),
 	}
+}
 
-	body := formatCodexRules(codexRules())
-	path := filepath.Join(dir, "default.rules")
-	if err := os.WriteFile(path, []byte(body), 0644); err != nil {
-		return err
+// renderCopilot renders a rule file in GitHub Copilot format at copilot/instructions/<name>.instructions.md.
+func renderCopilot(group RuleGroup, content string) renderedFile {
+	return renderedFile{
+		Target: "copilot",
+		Path:   "copilot/instructions/" + group.Name + ".instructions.md",
+		Body:   formatCopilotFrontmatter(group.Description, group.Globs, group.activation()) + content,
 	}
-	logger.WithFields(logger.Fields{
-		"path":  path,
-		"bytes": len(body),
-	}).Debug("wrote Codex rules file")
-	return nil
 }
 
-// writeCopilot writes a rule file in GitHub Copilot format to copilot/instructions/<name>.instructions.md.
-func writeCopilot(outputDir string, group RuleGroup, content string) error {
-	dir := filepath.Join(outputDir, "copilot", "instructions")
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("creating directory %s: %w", dir, err)
-	}
+// claudeRuleFrontmatter is the front matter of a Claude Code rule file.
+type claudeRuleFrontmatter struct {
+	Paths []string `yaml:"paths"` // globs of the files the rule is loaded for
+}
+
+// claudeSkillFrontmatter is the front matter of a Claude Code skill.
+type claudeSkillFrontmatter struct {
+	Name                   string `yaml:"name"`
+	Description            string `yaml:"description"`                        // what Claude picks the skill by
+	DisableModelInvocation bool   `yaml:"disable-model-invocation,omitempty"` // leaves the skill to the user
+}
 
-	body := formatCopilotFrontmatter(group.Globs) + content
+// cursorFrontmatter is the front matter of a Cursor rule file.
+type cursorFrontmatter struct {
+	Description string `yaml:"description,omitempty"`
+	Globs       string `yaml:"globs,omitempty"` // comma-separated, as Cursor reads them
+	AlwaysApply bool   `yaml:"alwaysApply"`
+}
 
-	path := filepath.Join(dir, group.Name+".instructions.md")
-	if err := os.WriteFile(path, []byte(body), 0644); err != nil {
-		return err
-	}
-	logger.WithFields(logger.Fields{
-		"path":  path,
-		"bytes": len(body),
-	}).Debug("wrote Copilot instruction file")
-	return nil
+// copilotFrontmatter is the front matter of a GitHub Copilot instruction file.
+type copilotFrontmatter struct {
+	ApplyTo     []string `yaml:"applyTo,omitempty"`
+	Description string   `yaml:"description,omitempty"`
 }
 
-// formatClaudeFrontmatter returns the frontmatter string for a Claude rule file.
-func formatClaudeFrontmatter(globs string) string {
-	if globs == "" {
+// formatClaudeFrontmatter returns the frontmatter string for a Claude rule file,
+// listing the globs under paths.
+func formatClaudeFrontmatter(globs GlobList) string {
+	if len(globs) == 0 {
 		return ""
 	}
-	return fmt.Sprintf("---\npaths:\n  - \"%s\"\n---\n\n", globs)
+	return formatFrontmatter(claudeRuleFrontmatter{Paths: globs.translate(claudeGlobs)})
 }
 
-// formatCursorFrontmatter returns the frontmatter string for a Cursor rule file.
-func formatCursorFrontmatter(description string, globs string) string {
-	if globs != "" {
-		return fmt.Sprintf("---\ndescription: \"%s\"\nglobs: \"%s\"\nalwaysApply: false\n---\n\n",
-			description, globs)
+// formatClaudeSkillFrontmatter returns the frontmatter string for a Claude skill. Claude picks
+// agent-requested skills by their description; manual skills run only as a slash command.
+func formatClaudeSkillFrontmatter(name string, description string, activation string) string {
+	return formatFrontmatter(claudeSkillFrontmatter{
+		Name:                   name,
+		Description:            description,
+		DisableModelInvocation: activation == activationManual,
+	})
+}
+
+// formatCursorFrontmatter returns the frontmatter string for a Cursor rule file, whose fields
+// select the rule type: always, auto attached (globs, read as one comma-separated string),
+// agent requested (a description only), or manual (neither).
+func formatCursorFrontmatter(description string, globs GlobList, activation string) string {
+	switch activation {
+	case activationGlob:
+		return formatFrontmatter(cursorFrontmatter{
+			Description: description,
+			Globs:       strings.Join(globs.translate(cursorGlobs), ","),
+		})
+	case activationAgentRequested:
+		return formatFrontmatter(cursorFrontmatter{Description: description})
+	case activationManual:
+		return formatFrontmatter(cursorFrontmatter{})
 	}
-	return fmt.Sprintf("---\ndescription: \"%s\"\nalwaysApply: true\n---\n\n", description)
+	return formatFrontmatter(cursorFrontmatter{Description: description, AlwaysApply: true})
 }
 
 // formatCopilotFrontmatter returns the frontmatter string for a GitHub Copilot instruction file.
-func formatCopilotFrontmatter(globs string) string {
-	if globs == "" {
+// Copilot applies a file to the paths listed under applyTo, and a file with only a description
+// is picked by the agent. Always-apply and manual groups get no front matter.
+func formatCopilotFrontmatter(description string, globs GlobList, activation string) string {
+	switch activation {
+	case activationGlob:
+		return formatFrontmatter(copilotFrontmatter{ApplyTo: globs.translate(copilotGlobs)})
+	case activationAgentRequested:
+		return formatFrontmatter(copilotFrontmatter{Description: description})
+	}
+	return ""
+}
+
+// formatGlobNotice returns a blockquote stating which files a rule applies to, for targets
+// that have no native glob activation. It returns an empty string for always-apply groups.
+func formatGlobNotice(globs GlobList) string {
+	if len(globs) == 0 {
 		return ""
 	}
-	return fmt.Sprintf("---\napplyTo: \"%s\"\n---\n\n", globs)
+	return fmt.Sprintf("> Applies to files matching %s.\n\n", globs.describe())
 }
@@ -10,17 +10,22 @@ import (
 func TestFormatClaudeFrontmatter(t *testing.T) {
 	tests := []struct {
 		name     string
-		globs    string
+		globs    GlobList
 		expected string
 	}{
 		{
 			name:     "with globs",
-			globs:    "**/*.go",
-			expected: "---\npaths:\n  - \"**/*.go\"\n---\n\n",
+			globs:    GlobList{"**/*.go"},
+			expected: "---\npaths:\n  - '**/*.go'\n---\n\n",
+		},
+		{
+			name:     "with several globs and an exclusion",
+			globs:    GlobList{"**/*.go", "!**/*_mock.go"},
+			expected: "---\npaths:\n  - '**/*.go'\n  - '!**/*_mock.go'\n---\n\n",
 		},
 		{
 			name:     "without globs",
-			globs:    "",
+			globs:    nil,
 			expected: "",
 		},
 	}
@@ -41,24 +46,80 @@ func TestFormatClaudeFrontmatter(t *testing.T) {
 	}
 }
 
+func TestRenderClaudeSkill(t *testing.T) {
+	tests := []struct {
+		name     string
+		group    RuleGroup
+		expected string
+	}{
+		{
+			name:     "agent-requested skill",
+			group:    RuleGroup{Name: "bulk-operations", Description: "Bulk operations", Activation: activationAgentRequested},
+			expected: "---\nname: 'bulk-operations'\ndescription: 'Bulk operations'\n---\n\n# Bulk\n",
+		},
+		{
+			name:     "manual skill hidden from the model",
+			group:    RuleGroup{Name: "release", Description: "Release checklist", Activation: activationManual},
+			expected: "---\nname: 'release'\ndescription: 'Release checklist'\ndisable-model-invocation: true\n---\n\n# Bulk\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			file := renderClaude(tt.group, "# Bulk\n")
+
+			// then
+			if want := "claude/skills/" + tt.group.Name + "/SKILL.md"; file.Path != want {
+				t.Errorf("path = %q, want %q", file.Path, want)
+			}
+			if file.Body != tt.expected {
+				t.Errorf("body\n  got:  %q\n  want: %q", file.Body, tt.expected)
+			}
+		})
+	}
+}
+
 func TestFormatCursorFrontmatter(t *testing.T) {
 	tests := []struct {
 		name        string
 		description string
-		globs       string
+		globs       GlobList
+		activation  string
 		expected    string
 	}{
 		{
 			name:        "language-specific with globs",
 			description: "Go language coding standards",
-			globs:       "**/*.go",
-			expected:    "---\ndescription: \"Go language coding standards\"\nglobs: \"**/*.go\"\nalwaysApply: false\n---\n\n",
+			globs:       GlobList{"**/*.go"},
+			activation:  activationGlob,
+			expected:    "---\ndescription: 'Go language coding standards'\nglobs: '**/*.go'\nalwaysApply: false\n---\n\n",
+		},
+		{
+			name:        "several globs joined by commas",
+			description: "YAML standards",
+			globs:       GlobList{"**/*.yaml", "!vendor/**"},
+			activation:  activationGlob,
+			expected:    "---\ndescription: 'YAML standards'\nglobs: '**/*.yaml,!vendor/**'\nalwaysApply: false\n---\n\n",
 		},
 		{
 			name:        "cross-cutting without globs",
 			description: "General code style conventions",
-			globs:       "",
-			expected:    "---\ndescription: \"General code style conventions\"\nalwaysApply: true\n---\n\n",
+			globs:       nil,
+			activation:  activationAlways,
+			expected:    "---\ndescription: 'General code style conventions'\nalwaysApply: true\n---\n\n",
+		},
+		{
+			name:        "agent-requested with a description only",
+			description: "Bulk operations",
+			activation:  activationAgentRequested,
+			expected:    "---\ndescription: 'Bulk operations'\nalwaysApply: false\n---\n\n",
+		},
+		{
+			name:        "manual without a description",
+			description: "Release checklist",
+			activation:  activationManual,
+			expected:    "---\nalwaysApply: false\n---\n\n",
 		},
 	}
 
@@ -67,19 +128,20 @@ func TestFormatCursorFrontmatter(t *testing.T) {
 			// given
 			description := tt.description
 			globs := tt.globs
+			activation := tt.activation
 
 			// when
-			result := formatCursorFrontmatter(description, globs)
+			result := formatCursorFrontmatter(description, globs, activation)
 
 			// then
 			if result != tt.expected {
-				t.Errorf("formatCursorFrontmatter(%q, %q)\n  got:  %q\n  want: %q", description, globs, result, tt.expected)
+				t.Errorf("formatCursorFrontmatter(%q, %q, %q)\n  got:  %q\n  want: %q", description, globs, activation, result, tt.expected)
 			}
 		})
 	}
 }
 
-func TestWriteClaude(t *testing.T) {
+func TestWriteRenderedClaude(t *testing.T) {
 	tests := []struct {
 		name          string
 		group         RuleGroup
@@ -91,11 +153,11 @@ func TestWriteClaude(t *testing.T) {
 			name: "language rule with globs frontmatter",
 			group: RuleGroup{
 				Name:  "golang",
-				Globs: "**/*.go",
+				Globs: GlobList{"**/*.go"},
 			},
 			content:       "# Go Standards\n\nUse gofmt.\n",
 			expectGlobs:   true,
-			expectContent: "---\npaths:\n  - \"**/*.go\"\n---\n\n# Go Standards\n\nUse gofmt.\n",
+			expectContent: "---\npaths:\n  - '**/*.go'\n---\n\n# Go Standards\n\nUse gofmt.\n",
 		},
 		{
 			name: "cross-cutting rule without frontmatter",
@@ -113,11 +175,11 @@ func TestWriteClaude(t *testing.T) {
 			tmpDir := t.TempDir()
 
 			// when
-			err := writeClaude(tmpDir, tt.group, tt.content)
+			err := writeRenderedFile(tmpDir, renderClaude(tt.group, tt.content))
 
 			// then
 			if err != nil {
-				t.Fatalf("writeClaude() error: %v", err)
+				t.Fatalf("writeRenderedFile() error: %v", err)
 			}
 			path := filepath.Join(tmpDir, "claude", "rules", tt.group.Name+".md")
 			data, err := os.ReadFile(path)
@@ -131,7 +193,7 @@ func TestWriteClaude(t *testing.T) {
 	}
 }
 
-func TestWriteCursor(t *testing.T) {
+func TestWriteRenderedCursor(t *testing.T) {
 	tests := []struct {
 		name         string
 		group        RuleGroup
@@ -143,10 +205,10 @@ func TestWriteCursor(t *testing.T) {
 			group: RuleGroup{
 				Name:        "golang",
 				Description: "Go coding standards",
-				Globs:       "**/*.go",
+				Globs:       GlobList{"**/*.go"},
 			},
 			content:      "# Go\n",
-			expectPrefix: "---\ndescription: \"Go coding standards\"\nglobs: \"**/*.go\"\nalwaysApply: false\n---\n\n",
+			expectPrefix: "---\ndescription: 'Go coding standards'\nglobs: '**/*.go'\nalwaysApply: false\n---\n\n",
 		},
 		{
 			name: "cross-cutting rule always apply",
@@ -155,7 +217,7 @@ func TestWriteCursor(t *testing.T) {
 				Description: "Testing standards",
 			},
 			content:      "# Testing\n",
-			expectPrefix: "---\ndescription: \"Testing standards\"\nalwaysApply: true\n---\n\n",
+			expectPrefix: "---\ndescription: 'Testing standards'\nalwaysApply: true\n---\n\n",
 		},
 	}
 
@@ -165,11 +227,11 @@ func TestWriteCursor(t *testing.T) {
 			tmpDir := t.TempDir()
 
 			// when
-			err := writeCursor(tmpDir, tt.group, tt.content)
+			err := writeRenderedFile(tmpDir, renderCursor(tt.group, tt.content))
 
 			// then
 			if err != nil {
-				t.Fatalf("writeCursor() error: %v", err)
+				t.Fatalf("writeRenderedFile() error: %v", err)
 			}
 			path := filepath.Join(tmpDir, "cursor", "rules", tt.group.Name+".mdc")
 			data, err := os.ReadFile(path)
@@ -185,39 +247,60 @@ func TestWriteCursor(t *testing.T) {
 
 func TestFormatCopilotFrontmatter(t *testing.T) {
 	tests := []struct {
-		name     string
-		globs    string
-		expected string
+		name        string
+		description string
+		globs       GlobList
+		activation  string
+		expected    string
 	}{
 		{
-			name:     "with globs",
-			globs:    "**/*.go",
-			expected: "---\napplyTo: \"**/*.go\"\n---\n\n",
+			name:       "with globs",
+			globs:      GlobList{"**/*.go"},
+			activation: activationGlob,
+			expected:   "---\napplyTo:\n  - '**/*.go'\n---\n\n",
 		},
 		{
-			name:     "without globs",
-			globs:    "",
-			expected: "",
+			name:       "with several globs",
+			globs:      GlobList{"**/*.ts", "**/*.tsx"},
+			activation: activationGlob,
+			expected:   "---\napplyTo:\n  - '**/*.ts'\n  - '**/*.tsx'\n---\n\n",
+		},
+		{
+			name:       "always-apply without frontmatter",
+			globs:      nil,
+			activation: activationAlways,
+			expected:   "",
+		},
+		{
+			name:        "agent-requested with a description only",
+			description: "Bulk operations",
+			activation:  activationAgentRequested,
+			expected:    "---\ndescription: 'Bulk operations'\n---\n\n",
+		},
+		{
+			name:       "manual without frontmatter",
+			activation: activationManual,
+			expected:   "",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// given
-			globs := tt.globs
+			description, globs, activation := tt.description, tt.globs, tt.activation
 
 			// when
-			result := formatCopilotFrontmatter(globs)
+			result := formatCopilotFrontmatter(description, globs, activation)
 
 			// then
 			if result != tt.expected {
-				t.Errorf("formatCopilotFrontmatter(%q)\n  got:  %q\n  want: %q", globs, result, tt.expected)
+				t.Errorf("formatCopilotFrontmatter(%q, %q, %q)\n  got:  %q\n  want: %q", description, globs, activation, result, tt.expected)
 			}
 		})
 	}
 }
 
-func TestWriteCopilot(t *testing.T) {
+func TestWriteRenderedCopilot(t *testing.T) {
 	tests := []struct {
 		name          string
 		group         RuleGroup
@@ -228,10 +311,10 @@ func TestWriteCopilot(t *testing.T) {
 			name: "language rule with applyTo frontmatter",
 			group: RuleGroup{
 				Name:  "golang",
-				Globs: "**/*.go",
+				Globs: GlobList{"**/*.go"},
 			},
 			content:       "# Go Standards\n\nUse gofmt.\n",
-			expectContent: "---\napplyTo: \"**/*.go\"\n---\n\n# Go Standards\n\nUse gofmt.\n",
+			expectContent: "---\napplyTo:\n  - '**/*.go'\n---\n\n# Go Standards\n\nUse gofmt.\n",
 		},
 		{
 			name: "cross-cutting rule without frontmatter",
@@ -241,6 +324,16 @@ func TestWriteCopilot(t *testing.T) {
 			content:       "# Code Style\n\nNaming conventions.\n",
 			expectContent: "# Code Style\n\nNaming conventions.\n",
 		},
+		{
+			name: "agent-requested rule described for the agent",
+			group: RuleGroup{
+				Name:        "bulk-operations",
+				Description: "Bulk operations",
+				Activation:  activationAgentRequested,
+			},
+			content:       "# Bulk\n",
+			expectContent: "---\ndescription: 'Bulk operations'\n---\n\n# Bulk\n",
+		},
 	}
 
 	for _, tt := range tests {
@@ -249,11 +342,11 @@ func TestWriteCopilot(t *testing.T) {
 			tmpDir := t.TempDir()
 
 			// when
-			err := writeCopilot(tmpDir, tt.group, tt.content)
+			err := writeRenderedFile(tmpDir, renderCopilot(tt.group, tt.content))
 
 			// then
 			if err != nil {
-				t.Fatalf("writeCopilot() error: %v", err)
+				t.Fatalf("writeRenderedFile() error: %v", err)
 			}
 			path := filepath.Join(tmpDir, "copilot", "instructions", tt.group.Name+".instructions.md")
 			data, err := os.ReadFile(path)
@@ -267,42 +360,6 @@ func TestWriteCopilot(t *testing.T) {
 	}
 }
 
-func TestWriteCodex(t *testing.T) {
-	// given
-	tmpDir := t.TempDir()
-	groups := []RuleGroup{
-		{Name: "code-style", Description: "Code style"},
-		{Name: "golang", Description: "Go standards"},
-	}
-	contents := []string{
-		"# Code Style\n\nNaming conventions.\n",
-		"# Go\n\nUse gofmt.\n",
-	}
-
-	// when
-	err := writeCodex(tmpDir, groups, contents)
-
-	// then
-	if err != nil {
-		t.Fatalf("writeCodex() error: %v", err)
-	}
-	path := filepath.Join(tmpDir, "codex", "AGENTS.md")
-	data, err := os.ReadFile(path)
-	if err != nil {
-		t.Fatalf("reading AGENTS.md: %v", err)
-	}
-	content := string(data)
-	if !strings.Contains(content, "# Code Style") {
-		t.Error("AGENTS.md should contain code-style content")
-	}
-	if !strings.Contains(content, "# Go") {
-		t.Error("AGENTS.md should contain golang content")
-	}
-	if !strings.Contains(content, "---") {
-		t.Error("AGENTS.md should contain separator between groups")
-	}
-}
-
 func TestFormatCodexRules(t *testing.T) {
 	// given
 	rules := []CodexRule{
@@ -365,16 +422,16 @@ func TestFormatStarlarkList(t *testing.T) {
 	}
 }
 
-func TestWriteCodexRules(t *testing.T) {
+func TestWriteRenderedCodexRules(t *testing.T) {
 	// given
 	tmpDir := t.TempDir()
 
 	// when
-	err := writeCodexRules(tmpDir)
+	err := writeRenderedFile(tmpDir, renderCodexRules())
 
 	// then
 	if err != nil {
-		t.Fatalf("writeCodexRules() error: %v", err)
+		t.Fatalf("writeRenderedFile() error: %v", err)
 	}
 	path := filepath.Join(tmpDir, "codex", "rules", "default.rules")
 	data, err := os.ReadFile(path)
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	logger "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// frontMatterFence opens and closes the YAML front matter at the top of a page.
+const frontMatterFence = "---"
+
+// pageMeta is the "ai" key of a source page's YAML front matter, which describes how the
+// page is turned into rules next to the page itself rather than in the rule groups:
+//
+//	---
+//	ai:
+//	  group: 'yaml'
+//	  description: 'YAML coding standards and conventions'
+//	  globs:
+//	    - '**/*.yml'
+//	    - '**/*.yaml'
+//	  priority: 1
+//	---
+//
+// Other front matter keys are left alone, so pages can carry metadata for other tools.
+type pageMeta struct {
+	Group       string   `yaml:"group,omitempty"`       // group the page belongs to; created when no group has that name
+	Description string   `yaml:"description,omitempty"` // overrides the group description
+	Globs       GlobList `yaml:"globs,omitempty"`       // overrides the group globs
+	Activation  string   `yaml:"activation,omitempty"`  // overrides the group activation
+	Priority    *int     `yaml:"priority,omitempty"`    // overrides the group priority
+	Exclude     bool     `yaml:"exclude,omitempty"`     // keeps the page out of every group, even when listed
+}
+
+// frontMatter is the decoded front matter of a page.
+type frontMatter struct {
+	AI    *pageMeta      `yaml:"ai"`
+	Other map[string]any `yaml:",inline"`
+}
+
+// splitFrontMatter separates the YAML front matter from the rest of a page. Front matter
+// starts on the first line with "---", followed directly by YAML, and ends at the next
+// "---" line; a page that merely starts with a thematic break has no front matter.
+func splitFrontMatter(content []byte) (front []byte, body []byte) {
+	opening, rest, ok := bytes.Cut(content, []byte("\n"))
+	if !ok || string(bytes.TrimRight(opening, " \t\r")) != frontMatterFence {
+		return nil, content
+	}
+	if first, _, _ := bytes.Cut(rest, []byte("\n")); len(bytes.TrimSpace(first)) == 0 {
+		return nil, content
+	}
+	for offset := 0; offset < len(rest); {
+		line, _, _ := bytes.Cut(rest[offset:], []byte("\n"))
+		next := offset + len(line) + 1
+		if string(bytes.TrimRight(line, " \t\r")) == frontMatterFence {
+			return rest[:offset], rest[min(next, len(rest)):]
+		}
+		offset = next
+	}
+	return nil, content
+}
+
+// parsePageMeta decodes the "ai" key of front matter. Unknown keys inside "ai" are
+// rejected so that typos fail loudly. It returns nil when there is no "ai" key.
+func parsePageMeta(front []byte) (*pageMeta, error) {
+	var matter frontMatter
+	decoder := yaml.NewDecoder(bytes.NewReader(front))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&matter); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return matter.AI, nil
+}
+
+// scanPageMeta reads the front matter of every markdown page below sourceDir and returns
+// the "ai" metadata by page path, relative to sourceDir. Hidden directories are skipped.
+func scanPageMeta(sourceDir string) (map[string]pageMeta, error) {
+	metas := make(map[string]pageMeta)
+	err := filepath.WalkDir(sourceDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != sourceDir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(entry.Name(), ".md") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		front, _ := splitFrontMatter(data)
+		if front == nil {
+			return nil
+		}
+		rel, err := filepath.Rel(sourceDir, path)
+		if err != nil {
+			return err
+		}
+		meta, err := parsePageMeta(front)
+		if err != nil {
+			return fmt.Errorf("front matter of %s: %w", filepath.ToSlash(rel), err)
+		}
+		if meta != nil {
+			metas[filepath.ToSlash(rel)] = *meta
+		}
+		return nil
+	})
+	return metas, err
+}
+
+// applyPageMeta merges page front matter into the rule groups. Excluded pages leave every
+// group; pages naming a group move to it (a new group is created, after the others, when no
+// group has that name); and description, globs, and priority override the values of the
+// group the page belongs to. When pages of one group disagree, the first page wins.
+func applyPageMeta(groups []RuleGroup, metas map[string]pageMeta) ([]RuleGroup, error) {
+	paths := make([]string, 0, len(metas))
+	for path := range metas {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	result := make([]RuleGroup, len(groups))
+	index := make(map[string]int, len(groups))
+	for i, group := range groups {
+		group.Sources = append([]string{}, group.Sources...)
+		result[i] = group
+		index[group.Name] = i
+	}
+	for _, path := range paths {
+		if !metas[path].Exclude {
+			continue
+		}
+		for i := range result {
+			result[i].Sources = slices.DeleteFunc(result[i].Sources, func(src string) bool { return src == path })
+		}
+		logger.WithFields(logger.Fields{
+			"source": path,
+		}).Debug("excluded page through front matter")
+	}
+
+	var errs []error
+	overridden := make(map[string]string) // group name -> page whose front matter set its metadata
+	for _, path := range paths {
+		meta := metas[path]
+		if meta.Exclude {
+			continue
+		}
+		name := meta.Group
+		if name == "" {
+			name = owningGroup(result, path)
+		}
+		if name == "" {
+			errs = append(errs, fmt.Errorf("%s: front matter must name a group, since no group lists the page", path))
+			continue
+		}
+		if !groupNameRegex.MatchString(name) {
+			errs = append(errs, fmt.Errorf("%s: group %q must be lowercase kebab-case", path, name))
+			continue
+		}
+		if err := meta.Globs.validate(path + ": ai"); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		i, ok := index[name]
+		if !ok {
+			if strings.TrimSpace(meta.Description) == "" {
+				errs = append(errs, fmt.Errorf("%s: front matter creates group %q and needs a description", path, name))
+				continue
+			}
+			index[name] = len(result)
+			i = len(result)
+			result = append(result, RuleGroup{Name: name})
+		}
+		if meta.Group != "" {
+			for j := range result {
+				if j != i {
+					result[j].Sources = slices.DeleteFunc(result[j].Sources, func(src string) bool { return src == path })
+				}
+			}
+		}
+		group := &result[i]
+		if !slices.Contains(group.Sources, path) {
+			group.Sources = append(group.Sources, path)
+		}
+
+		if meta.Description == "" && len(meta.Globs) == 0 && meta.Activation == "" && meta.Priority == nil {
+			continue
+		}
+		if first, ok := overridden[name]; ok {
+			logger.WithFields(logger.Fields{
+				"group":  name,
+				"source": path,
+				"kept":   first,
+			}).Warn("ignored group metadata from a second page's front matter")
+			continue
+		}
+		overridden[name] = path
+		if meta.Description != "" {
+			group.Description = meta.Description
+		}
+		if len(meta.Globs) > 0 {
+			group.Globs = meta.Globs
+		}
+		if meta.Activation != "" {
+			group.Activation = meta.Activation
+		}
+		if meta.Priority != nil {
+			group.Priority = *meta.Priority
+		}
+		if err := validateActivation(*group, path+": ai"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// owningGroup returns the name of the first group listing path, or an empty string.
+func owningGroup(groups []RuleGroup, path string) string {
+	for _, group := range groups {
+		if slices.Contains(group.Sources, path) {
+			return group.Name
+		}
+	}
+	return ""
+}
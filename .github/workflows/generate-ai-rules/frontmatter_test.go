@@ -0,0 +1,155 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		expectedFront string
+		expectedBody  string
+	}{
+		{
+			name:          "front matter removed from the body",
+			input:         "---\nai:\n  group: 'yaml'\n---\n# YAML\n",
+			expectedFront: "ai:\n  group: 'yaml'\n",
+			expectedBody:  "# YAML\n",
+		},
+		{
+			name:         "page without front matter",
+			input:        "# YAML\n\n---\n\nText.\n",
+			expectedBody: "# YAML\n\n---\n\nText.\n",
+		},
+		{
+			name:         "leading thematic break is not front matter",
+			input:        "---\n\n<p>Footer</p>\n\n---\n",
+			expectedBody: "---\n\n<p>Footer</p>\n\n---\n",
+		},
+		{
+			name:         "unclosed front matter kept as written",
+			input:        "---\nai:\n# YAML\n",
+			expectedBody: "---\nai:\n# YAML\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			front, body := splitFrontMatter([]byte(tt.input))
+
+			// then
+			if string(front) != tt.expectedFront || string(body) != tt.expectedBody {
+				t.Errorf("splitFrontMatter() = %q, %q; want %q, %q", front, body, tt.expectedFront, tt.expectedBody)
+			}
+		})
+	}
+}
+
+func TestParsePageMeta(t *testing.T) {
+	// given
+	front := "title: 'YAML'\nai:\n  group: 'yaml'\n  priority: 2\n  globs: '**/*.yaml'\n"
+
+	// when
+	meta, err := parsePageMeta([]byte(front))
+
+	// then
+	if err != nil {
+		t.Fatalf("parsePageMeta() error: %v", err)
+	}
+	if meta == nil || meta.Group != "yaml" || strings.Join(meta.Globs, ",") != "**/*.yaml" || meta.Priority == nil || *meta.Priority != 2 {
+		t.Errorf("parsePageMeta() = %+v", meta)
+	}
+
+	if meta, err := parsePageMeta([]byte("title: 'YAML'\n")); err != nil || meta != nil {
+		t.Errorf("front matter without an ai key should give no metadata, got %+v, %v", meta, err)
+	}
+	if _, err := parsePageMeta([]byte("ai:\n  groups: 'yaml'\n")); err == nil || !strings.Contains(err.Error(), "field groups not found") {
+		t.Errorf("unknown ai keys should be rejected, got %v", err)
+	}
+}
+
+func TestApplyPageMeta(t *testing.T) {
+	// given
+	groups := []RuleGroup{
+		{Name: "git-flow", Description: "Git", Sources: []string{"Git-Flow.md", "Merge-Guide.md", "History.md"}},
+		{Name: "testing", Description: "Tests", Sources: []string{"Tests.md", "Doubles.md"}},
+	}
+	priority := 5
+	metas := map[string]pageMeta{
+		"History.md":  {Exclude: true},
+		"Doubles.md":  {Group: "git-flow"},
+		"Tests.md":    {Description: "Testing standards", Priority: &priority, Activation: activationManual},
+		"Cookbook.md": {Group: "cookbooks", Description: "Cookbooks", Globs: GlobList{"**/*.sh"}},
+	}
+
+	// when
+	result, err := applyPageMeta(groups, metas)
+
+	// then
+	if err != nil {
+		t.Fatalf("applyPageMeta() error: %v", err)
+	}
+	if len(result) != 3 {
+		t.Fatalf("applyPageMeta() returned %d groups, want 3", len(result))
+	}
+	if got := strings.Join(result[0].Sources, ","); got != "Git-Flow.md,Merge-Guide.md,Doubles.md" {
+		t.Errorf("git-flow sources = %s", got)
+	}
+	if got := strings.Join(result[1].Sources, ","); got != "Tests.md" {
+		t.Errorf("testing sources = %s", got)
+	}
+	if result[1].Description != "Testing standards" || result[1].Priority != 5 || result[1].Activation != activationManual {
+		t.Errorf("testing metadata not overridden: %+v", result[1])
+	}
+	created := result[2]
+	if created.Name != "cookbooks" || created.Description != "Cookbooks" || strings.Join(created.Globs, ",") != "**/*.sh" || strings.Join(created.Sources, ",") != "Cookbook.md" {
+		t.Errorf("unexpected created group: %+v", created)
+	}
+	if len(groups[0].Sources) != 3 {
+		t.Error("applyPageMeta() should not modify the groups it is given")
+	}
+}
+
+func TestApplyPageMetaErrors(t *testing.T) {
+	// given
+	metas := map[string]pageMeta{
+		"Orphan.md":  {Description: "No group"},
+		"New.md":     {Group: "new-group"},
+		"Invalid.md": {Group: "Not Kebab"},
+		"Manual.md":  {Group: "manual", Description: "Manual", Globs: GlobList{"**/*.sh"}, Activation: activationManual},
+	}
+
+	// when
+	_, err := applyPageMeta(nil, metas)
+
+	// then
+	if err == nil {
+		t.Fatal("applyPageMeta() should fail")
+	}
+	for _, expected := range []string{"Orphan.md: front matter must name a group", "needs a description", "must be lowercase kebab-case", `Manual.md: ai.globs only apply to the "glob" activation, not "manual"`} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("error %q should contain %q", err, expected)
+		}
+	}
+}
+
+func TestProcessGroupStripsFrontMatter(t *testing.T) {
+	// given
+	tmpDir := t.TempDir()
+	writeTestFile(t, tmpDir, "Page.md", "---\nai:\n  group: 'page'\n---\n# Page\n\nRule.\n")
+	group := RuleGroup{Name: "page", Description: "Page", Sources: []string{"Page.md"}}
+
+	// when
+	merged, _, err := processGroup(tmpDir, group, []Target{claudeTarget{}}, newLinkResolver(nil, ""), newHTMLSanitizer(defaultAllowedHTML))
+
+	// then
+	if err != nil {
+		t.Fatalf("processGroup() error: %v", err)
+	}
+	if result := merged["claude"]; strings.Contains(result, "ai:") || strings.Contains(result, "---") {
+		t.Errorf("front matter should not reach the rules, got:\n%s", result)
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+// geminiMaxSize is the budget for everything a single GEMINI.md pulls in through imports.
+// Gemini CLI has no hard limit, but the whole context hierarchy is sent with every prompt.
+const geminiMaxSize = 64 * 1024 // 64 KiB
+
+// geminiTarget emits one fragment per group plus GEMINI.md context files that @import them.
+// Groups whose globs are rooted in a fixed directory get a context file in that directory,
+// so Gemini CLI only loads them when working below it. Other language groups and the
+// agent-requested and manual groups are not imported but listed in the root GEMINI.md,
+// for Gemini CLI to read when relevant.
+type geminiTarget struct{}
+
+func (geminiTarget) Name() string    { return "gemini" }
+func (geminiTarget) Paths() []string { return []string{"gemini"} }
+
+// Reference avoids the @path syntax, which Gemini CLI would treat as an import.
+func (geminiTarget) Reference(group RuleGroup) string { return "see the " + group.Name + " rules" }
+
+func (geminiTarget) RenderGroup(group RuleGroup, content string) []renderedFile {
+	return []renderedFile{renderGeminiFragment(group, content)}
+}
+
+func (geminiTarget) RenderAggregate(groups []RuleGroup, contents []string) ([]renderedFile, error) {
+	return renderGeminiContexts(groups, contents)
+}
+
+// renderGeminiFragment renders a group as gemini/rules/<name>.md. Since Gemini CLI has no
+// glob-based activation, language groups state the files they apply to in the text.
+func renderGeminiFragment(group RuleGroup, content string) renderedFile {
+	return renderedFile{
+		Target: "gemini",
+		Path:   geminiFragmentPath(group),
+		Body:   formatGlobNotice(group.Globs) + content,
+	}
+}
+
+// geminiFragmentPath returns the path of a group's Gemini fragment.
+func geminiFragmentPath(group RuleGroup) string {
+	return "gemini/rules/" + group.Name + ".md"
+}
+
+// renderGeminiContexts renders gemini/GEMINI.md and any per-directory gemini/<dir>/GEMINI.md
+// files. Each context file imports its fragments with Gemini's @path syntax, resolved
+// relative to the importing file, highest priority first. Since Gemini CLI has no glob
+// activation, language groups whose globs can match anywhere are listed in the root index
+// instead of imported, like the agent-requested and manual groups. Groups that do not fit
+// the geminiMaxSize budget of their context file, provenance blocks included, are listed
+// in that file's index as well, so lower-priority groups are the ones left out.
+func renderGeminiContexts(groups []RuleGroup, contents []string) ([]renderedFile, error) {
+	imports := make(map[string][]int) // context directory -> imported group indexes
+	listed := make(map[string][]int)  // context directory -> listed group indexes
+	for _, i := range priorityOrder(groups) {
+		if contents[i] == "" {
+			continue
+		}
+		switch dir := groups[i].Globs.directory(); {
+		case groups[i].onDemand(), len(groups[i].Globs) > 0 && dir == "":
+			listed[""] = append(listed[""], i)
+		default:
+			imports[dir] = append(imports[dir], i)
+		}
+	}
+
+	dirs := make([]string, 0, len(imports)+1)
+	for dir := range imports {
+		dirs = append(dirs, dir)
+	}
+	if _, ok := imports[""]; !ok && len(listed[""]) > 0 {
+		dirs = append(dirs, "") // the root context file holds the index
+	}
+	sort.Strings(dirs)
+
+	files := make([]renderedFile, 0, len(dirs))
+	var errs []error
+	for _, dir := range dirs {
+		contextPath := path.Join("gemini", dir, "GEMINI.md")
+		link := func(group RuleGroup) string {
+			return relativeImport(path.Dir(contextPath), geminiFragmentPath(group))
+		}
+		candidates := imports[dir]
+		all := append(append([]int{}, listed[dir]...), candidates...)
+
+		// reserve room for the worst-case index, where every imported group is listed too,
+		// and for the provenance block of the context file itself
+		budget := geminiMaxSize - len(formatRuleIndex(groups, all, link)) - provenanceSize(groupSources(groups, all))
+		var imported []int
+		var size int
+		for _, i := range candidates {
+			fragment := renderGeminiFragment(groups[i], contents[i])
+			extra := len(fragment.Body) + provenanceSize(groups[i].Sources)
+			if size+extra > budget {
+				logger.WithFields(logger.Fields{
+					"path":        contextPath,
+					"group":       groups[i].Name,
+					"priority":    groups[i].Priority,
+					"size_bytes":  extra,
+					"limit_bytes": geminiMaxSize,
+				}).Warn("listed group instead of importing it to fit the Gemini size budget")
+				listed[dir] = append(listed[dir], i)
+				continue
+			}
+			imported = append(imported, i)
+			size += extra
+		}
+
+		var sb strings.Builder
+		for n, i := range imported {
+			if n > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString("@" + link(groups[i]) + "\n")
+		}
+		if index := formatRuleIndex(groups, listed[dir], link); index != "" {
+			if sb.Len() > 0 {
+				sb.WriteString("\n")
+			}
+			sb.WriteString(index)
+		}
+		indexes := append(append([]int{}, imported...), listed[dir]...)
+		size += sb.Len() + provenanceSize(groupSources(groups, indexes))
+		if size > geminiMaxSize {
+			logger.WithFields(logger.Fields{
+				"path":        contextPath,
+				"size_bytes":  size,
+				"limit_bytes": geminiMaxSize,
+			}).Warn("GEMINI.md imports exceed the Gemini size budget")
+			errs = append(errs, fmt.Errorf("%w: %s imports %d bytes (limit %d)", errBudgetExceeded, contextPath, size, geminiMaxSize))
+		}
+		files = append(files, renderedFile{
+			Target:  "gemini",
+			Path:    contextPath,
+			Body:    sb.String(),
+			Sources: groupSources(groups, indexes),
+		})
+	}
+	return files, errors.Join(errs...)
+}
+
+// globDirectory returns the directory prefix of a glob that contains no wildcards,
+// e.g. "docs/**/*.md" -> "docs". Globs that can match anywhere return "".
+func globDirectory(glob string) string {
+	segments := strings.Split(glob, "/")
+	var static []string
+	for _, segment := range segments[:len(segments)-1] {
+		if strings.ContainsAny(segment, "*?[{") {
+			break
+		}
+		static = append(static, segment)
+	}
+	return path.Join(static...)
+}
+
+// relativeImport returns target as a "./"- or "../"-prefixed path relative to fromDir.
+func relativeImport(fromDir string, target string) string {
+	from := strings.Split(fromDir, "/")
+	to := strings.Split(target, "/")
+	common := 0
+	for common < len(from) && common < len(to)-1 && from[common] == to[common] {
+		common++
+	}
+	rel := strings.Repeat("../", len(from)-common) + strings.Join(to[common:], "/")
+	if !strings.HasPrefix(rel, "../") {
+		rel = "./" + rel
+	}
+	return rel
+}
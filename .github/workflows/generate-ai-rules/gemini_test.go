@@ -0,0 +1,168 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRenderGeminiContexts(t *testing.T) {
+	// given
+	groups := []RuleGroup{
+		{Name: "code-style", Description: "Code style"},
+		{Name: "golang", Description: "Go standards", Globs: GlobList{"**/*.go"}},
+		{Name: "docs", Description: "Docs", Globs: GlobList{"docs/**/*.md"}},
+		{Name: "empty", Description: "Empty group"},
+		{Name: "bulk-operations", Description: "Bulk operations", Activation: activationAgentRequested},
+	}
+	contents := []string{"# Code Style\n", "# Go\n", "# Docs\n", "", "# Bulk\n"}
+
+	// when
+	files, err := renderGeminiContexts(groups, contents)
+
+	// then
+	if err != nil {
+		t.Fatalf("renderGeminiContexts() error: %v", err)
+	}
+	expected := map[string]string{
+		"gemini/GEMINI.md": "@./rules/code-style.md\n\n## Additional Rules\n\n" +
+			"Read the linked file before working on the matching files or topics:\n\n" +
+			"- `./rules/golang.md`: Go standards (files matching `**/*.go`)\n" +
+			"- `./rules/bulk-operations.md`: Bulk operations\n",
+		"gemini/docs/GEMINI.md": "@../rules/docs.md\n",
+	}
+	if len(files) != len(expected) {
+		t.Fatalf("renderGeminiContexts() returned %d files, want %d: %+v", len(files), len(expected), files)
+	}
+	for _, file := range files {
+		if want, ok := expected[file.Path]; !ok || file.Body != want {
+			t.Errorf("%s\n  got:  %q\n  want: %q", file.Path, file.Body, want)
+		}
+	}
+}
+
+func TestRenderGeminiContextsListsGroupsOverBudget(t *testing.T) {
+	// given
+	groups := []RuleGroup{
+		{Name: "testing", Description: "Testing", Sources: []string{"Tests.md"}, Priority: 1},
+		{Name: "code-style", Description: "Code style", Sources: []string{"Code-Style.md"}, Priority: 2},
+		{Name: "security", Description: "Security", Sources: []string{"Security.md"}},
+	}
+	// code-style and security fit the budget together, testing does not fit with code-style
+	contents := []string{
+		strings.Repeat("x", geminiMaxSize/2),
+		strings.Repeat("x", geminiMaxSize/2),
+		strings.Repeat("x", geminiMaxSize/4),
+	}
+
+	// when
+	files, err := renderGeminiContexts(groups, contents)
+
+	// then
+	if err != nil {
+		t.Fatalf("renderGeminiContexts() error: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("renderGeminiContexts() returned %d files, want 1", len(files))
+	}
+	expected := "@./rules/code-style.md\n\n@./rules/security.md\n\n## Additional Rules\n\n" +
+		"Read the linked file before working on the matching files or topics:\n\n" +
+		"- `./rules/testing.md`: Testing\n"
+	if files[0].Body != expected {
+		t.Errorf("gemini/GEMINI.md\n  got:  %q\n  want: %q", files[0].Body, expected)
+	}
+}
+
+func TestRenderGeminiContextsReportsUnmetBudget(t *testing.T) {
+	// given
+	groups := []RuleGroup{
+		{Name: "code-style", Description: "Code style", Sources: []string{"Code-Style.md"}},
+		{Name: "docs", Description: "Docs", Globs: GlobList{"docs/**/*.md"}, Sources: []string{"Docs.md"}},
+	}
+	// the index of each context file alone is over the budget
+	for i := range groups {
+		groups[i].Description = strings.Repeat("x", geminiMaxSize)
+	}
+	contents := []string{"# Code Style\n", "# Docs\n"}
+
+	// when
+	files, err := renderGeminiContexts(groups, contents)
+
+	// then
+	if !errors.Is(err, errBudgetExceeded) {
+		t.Errorf("renderGeminiContexts() error = %v, want errBudgetExceeded", err)
+	}
+	for _, path := range []string{"gemini/GEMINI.md", "gemini/docs/GEMINI.md"} {
+		if err == nil || !strings.Contains(err.Error(), path) {
+			t.Errorf("renderGeminiContexts() error = %v, want it to report %s", err, path)
+		}
+	}
+	if len(files) != 2 {
+		t.Errorf("renderGeminiContexts() returned %d files, want the context files rendered anyway", len(files))
+	}
+}
+
+func TestRenderGeminiFragment(t *testing.T) {
+	tests := []struct {
+		name     string
+		group    RuleGroup
+		expected string
+	}{
+		{
+			name:     "always-apply group keeps content",
+			group:    RuleGroup{Name: "code-style"},
+			expected: "# Rules\n",
+		},
+		{
+			name:     "language group states where it applies",
+			group:    RuleGroup{Name: "golang", Globs: GlobList{"**/*.go"}},
+			expected: "> Applies to files matching `**/*.go`.\n\n# Rules\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			content := "# Rules\n"
+
+			// when
+			file := renderGeminiFragment(tt.group, content)
+
+			// then
+			if file.Path != "gemini/rules/"+tt.group.Name+".md" {
+				t.Errorf("path = %q", file.Path)
+			}
+			if file.Body != tt.expected {
+				t.Errorf("body\n  got:  %q\n  want: %q", file.Body, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGlobDirectory(t *testing.T) {
+	tests := []struct {
+		glob     string
+		expected string
+	}{
+		{glob: "**/*.go", expected: ""},
+		{glob: "**/*.{yml,yaml}", expected: ""},
+		{glob: "docs/**/*.md", expected: "docs"},
+		{glob: "services/api/*.py", expected: "services/api"},
+		{glob: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.glob, func(t *testing.T) {
+			// given
+			glob := tt.glob
+
+			// when
+			result := globDirectory(glob)
+
+			// then
+			if result != tt.expected {
+				t.Errorf("globDirectory(%q) = %q, want %q", glob, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// maxGlobExpansions bounds the patterns a brace expression may expand to.
+const maxGlobExpansions = 64
+
+// globDialect describes the glob syntax an assistant understands. Every dialect handles
+// "*", "**", "?", character classes, and a leading "!"; they differ on brace expansion.
+type globDialect struct {
+	braces bool // expands {a,b} alternatives itself
+}
+
+// Glob dialects of the targets with native glob activation. Cursor, Copilot, and Windsurf
+// split their globs on commas, so an alternative such as "**/*.{yml,yaml}" would be cut in
+// two; their patterns are expanded instead.
+var (
+	claudeGlobs   = globDialect{braces: true}
+	cursorGlobs   = globDialect{braces: false}
+	copilotGlobs  = globDialect{braces: false}
+	windsurfGlobs = globDialect{braces: false}
+	continueGlobs = globDialect{braces: true}
+)
+
+// globKind identifies the elements of a parsed glob.
+type globKind int
+
+const (
+	globLiteral    globKind = iota // text matched as written
+	globStar                       // "*": any characters except "/"
+	globDoubleStar                 // "**": any number of whole path segments
+	globAnyChar                    // "?": one character except "/"
+	globClass                      // "[a-z]" or "[!a-z]": one character of a set
+	globBraces                     // "{a,b}": one of several alternatives
+)
+
+// globPart is an element of a parsed glob.
+type globPart struct {
+	kind    globKind
+	text    string       // literal text (unescaped) or the class as written
+	choices [][]globPart // alternatives of a brace expression
+}
+
+// glob is a parsed, validated pattern of a GlobList.
+type glob struct {
+	negated bool
+	parts   []globPart
+	match   *regexp.Regexp // anchored matcher for slash-separated paths relative to the repository root
+}
+
+// parsedGlobs caches parsed patterns, so each pattern is parsed once however many targets
+// translate it.
+var parsedGlobs sync.Map // pattern -> glob
+
+// parseGlob parses and validates a pattern. Patterns are relative to the repository root,
+// "**" must be a whole path segment, and commas may only separate brace alternatives,
+// since several targets join their patterns with commas.
+func parseGlob(pattern string) (glob, error) {
+	if cached, ok := parsedGlobs.Load(pattern); ok {
+		return cached.(glob), nil
+	}
+	body, negated := strings.CutPrefix(pattern, "!")
+	if strings.TrimSpace(body) == "" {
+		return glob{}, errors.New("must not be empty")
+	}
+	if strings.HasPrefix(body, "/") {
+		return glob{}, errors.New("must be relative to the repository root")
+	}
+	for _, segment := range strings.Split(body, "/") {
+		if segment == "." || segment == ".." {
+			return glob{}, errors.New("must not contain . or .. segments")
+		}
+	}
+
+	p := globParser{source: body}
+	parts, err := p.sequence(false)
+	if err != nil {
+		return glob{}, err
+	}
+	if count := expansionCount(parts); count > maxGlobExpansions {
+		return glob{}, fmt.Errorf("expands to %d patterns (limit %d)", count, maxGlobExpansions)
+	}
+
+	match, err := regexp.Compile("^" + globRegexp(parts) + "$")
+	if err != nil {
+		return glob{}, fmt.Errorf("does not compile: %w", err)
+	}
+	parsed := glob{negated: negated, parts: parts, match: match}
+	parsedGlobs.Store(pattern, parsed)
+	return parsed, nil
+}
+
+// globParser is a recursive descent parser over the text of a pattern.
+type globParser struct {
+	source string
+	pos    int
+}
+
+// sequence parses parts up to the end of the pattern or, inside braces, up to the next
+// "," or "}", which is left for the caller.
+func (p *globParser) sequence(inBraces bool) ([]globPart, error) {
+	var parts []globPart
+	literal := func(text string) {
+		if n := len(parts); n > 0 && parts[n-1].kind == globLiteral {
+			parts[n-1].text += text
+			return
+		}
+		parts = append(parts, globPart{kind: globLiteral, text: text})
+	}
+
+	for p.pos < len(p.source) {
+		c := p.source[p.pos]
+		switch c {
+		case '\\':
+			if p.pos+1 >= len(p.source) {
+				return nil, errors.New("must not end with an escape")
+			}
+			literal(p.source[p.pos+1 : p.pos+2])
+			p.pos += 2
+		case '*':
+			if !strings.HasPrefix(p.source[p.pos:], "**") {
+				parts = append(parts, globPart{kind: globStar})
+				p.pos++
+				continue
+			}
+			if !p.boundary(p.pos-1) || !p.boundary(p.pos+2) {
+				return nil, fmt.Errorf("** must be a whole path segment at offset %d", p.pos)
+			}
+			parts = append(parts, globPart{kind: globDoubleStar})
+			p.pos += 2
+		case '?':
+			parts = append(parts, globPart{kind: globAnyChar})
+			p.pos++
+		case '[':
+			end := p.pos + 1
+			if end < len(p.source) && p.source[end] == '!' {
+				end++
+			}
+			if end < len(p.source) && p.source[end] == ']' {
+				end++ // a leading "]" is part of the set
+			}
+			closing := strings.IndexByte(p.source[end:], ']')
+			if closing < 0 {
+				return nil, fmt.Errorf("unclosed [ at offset %d", p.pos)
+			}
+			end += closing + 1
+			class := p.source[p.pos:end]
+			if _, err := regexp.Compile(classRegexp(class)); err != nil {
+				return nil, fmt.Errorf("invalid character class %s at offset %d", class, p.pos)
+			}
+			parts = append(parts, globPart{kind: globClass, text: class})
+			p.pos = end
+		case '{':
+			braces, err := p.braces()
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, braces)
+		case ',', '}':
+			if inBraces {
+				return parts, nil
+			}
+			if c == ',' {
+				return nil, fmt.Errorf("comma at offset %d is outside braces; list each pattern separately", p.pos)
+			}
+			return nil, fmt.Errorf("unmatched } at offset %d", p.pos)
+		default:
+			literal(p.source[p.pos : p.pos+1])
+			p.pos++
+		}
+	}
+	if inBraces {
+		return nil, errors.New("unclosed {")
+	}
+	return parts, nil
+}
+
+// braces parses a brace expression starting at the current "{".
+func (p *globParser) braces() (globPart, error) {
+	open := p.pos
+	p.pos++
+	part := globPart{kind: globBraces}
+	for {
+		choice, err := p.sequence(true)
+		if err != nil {
+			return globPart{}, err
+		}
+		part.choices = append(part.choices, choice)
+		closing := p.source[p.pos] == '}'
+		p.pos++
+		if closing {
+			break
+		}
+	}
+	if len(part.choices) < 2 {
+		return globPart{}, fmt.Errorf("braces at offset %d need at least two alternatives", open)
+	}
+	return part, nil
+}
+
+// boundary reports whether offset i is outside the pattern or holds a "/". Requiring a "/"
+// rather than a brace around "**" keeps it a whole segment once braces are expanded.
+func (p *globParser) boundary(i int) bool {
+	return i < 0 || i >= len(p.source) || p.source[i] == '/'
+}
+
+// expansionCount returns the number of patterns parts expand to without braces.
+func expansionCount(parts []globPart) int {
+	count := 1
+	for _, part := range parts {
+		if part.kind != globBraces {
+			continue
+		}
+		alternatives := 0
+		for _, choice := range part.choices {
+			alternatives += expansionCount(choice)
+		}
+		count *= alternatives
+	}
+	return count
+}
+
+// expand returns every brace-free sequence parts can stand for, in order.
+func expand(parts []globPart) [][]globPart {
+	expanded := [][]globPart{nil}
+	for _, part := range parts {
+		if part.kind != globBraces {
+			for i := range expanded {
+				expanded[i] = append(expanded[i], part)
+			}
+			continue
+		}
+		var next [][]globPart
+		for _, prefix := range expanded {
+			for _, choice := range part.choices {
+				for _, suffix := range expand(choice) {
+					next = append(next, append(append([]globPart{}, prefix...), suffix...))
+				}
+			}
+		}
+		expanded = next
+	}
+	return expanded
+}
+
+// formatGlob writes parts back as a pattern, escaping literal special characters.
+func formatGlob(negated bool, parts []globPart) string {
+	var sb strings.Builder
+	if negated {
+		sb.WriteString("!")
+	}
+	for i, part := range parts {
+		switch part.kind {
+		case globLiteral:
+			for j, r := range part.text {
+				if strings.ContainsRune(`\*?[{},`, r) || (r == '!' && i == 0 && j == 0) {
+					sb.WriteByte('\\')
+				}
+				sb.WriteRune(r)
+			}
+		case globStar:
+			sb.WriteString("*")
+		case globDoubleStar:
+			sb.WriteString("**")
+		case globAnyChar:
+			sb.WriteString("?")
+		case globClass:
+			sb.WriteString(part.text)
+		case globBraces:
+			choices := make([]string, len(part.choices))
+			for j, choice := range part.choices {
+				choices[j] = formatGlob(false, choice)
+			}
+			sb.WriteString("{" + strings.Join(choices, ",") + "}")
+		}
+	}
+	return sb.String()
+}
+
+// globRegexp translates parts into a regular expression. "**/" matches zero or more
+// whole segments, so "**/*.go" matches "main.go" as well as "cmd/app/main.go".
+func globRegexp(parts []globPart) string {
+	var sb strings.Builder
+	segments := false // the previous part was "**" and consumed the "/" that follows it
+	for i, part := range parts {
+		switch part.kind {
+		case globLiteral:
+			text := part.text
+			if segments {
+				text = text[1:]
+			}
+			sb.WriteString(regexp.QuoteMeta(text))
+		case globStar:
+			sb.WriteString("[^/]*")
+		case globDoubleStar:
+			if i+1 < len(parts) && parts[i+1].kind == globLiteral && strings.HasPrefix(parts[i+1].text, "/") {
+				sb.WriteString("(?:.*/)?")
+				segments = true
+				continue
+			}
+			sb.WriteString(".*")
+		case globAnyChar:
+			sb.WriteString("[^/]")
+		case globClass:
+			sb.WriteString(classRegexp(part.text))
+		case globBraces:
+			choices := make([]string, len(part.choices))
+			for j, choice := range part.choices {
+				choices[j] = globRegexp(choice)
+			}
+			sb.WriteString("(?:" + strings.Join(choices, "|") + ")")
+		}
+		segments = false
+	}
+	return sb.String()
+}
+
+// classRegexp translates a character class such as "[!a-z]" into a regular expression.
+func classRegexp(class string) string {
+	set := class[1 : len(class)-1]
+	if negatedSet, ok := strings.CutPrefix(set, "!"); ok {
+		set = "^" + negatedSet
+	}
+	return "[" + strings.ReplaceAll(set, `\`, `\\`) + "]"
+}
+
+// translate rewrites the list for a dialect, expanding brace alternatives into separate
+// patterns when the dialect cannot read them. Patterns that do not parse are kept as
+// written, since validation reports them before any file is rendered.
+func (g GlobList) translate(dialect globDialect) GlobList {
+	translated := make(GlobList, 0, len(g))
+	for _, pattern := range g {
+		parsed, err := parseGlob(pattern)
+		if err != nil || dialect.braces {
+			translated = append(translated, pattern)
+			continue
+		}
+		for _, parts := range expand(parsed.parts) {
+			translated = append(translated, formatGlob(parsed.negated, parts))
+		}
+	}
+	return translated
+}
+
+// matches reports whether the list selects a slash-separated path relative to the
+// repository root: some pattern includes it and no negated pattern excludes it.
+func (g GlobList) matches(path string) bool {
+	included := false
+	for _, pattern := range g {
+		parsed, err := parseGlob(pattern)
+		if err != nil {
+			continue
+		}
+		if parsed.match.MatchString(path) {
+			if parsed.negated {
+				return false
+			}
+			included = true
+		}
+	}
+	return included
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// globSamplePaths are the repository paths every translated glob is checked against.
+var globSamplePaths = []string{
+	"main.go",
+	"cmd/app/main.go",
+	"internal/user_mock.go",
+	"config.yml",
+	"deploy/values.yaml",
+	"vendor/lib/config.yaml",
+	"web/src/App.tsx",
+	"web/src/index.ts",
+	"web/src/util.js",
+	"docs/Guide.md",
+	"docs/drafts/Idea.md",
+	"README.md",
+	"a,b.txt",
+	"file[1].txt",
+}
+
+func TestParseGlobRejectsInvalidPatterns(t *testing.T) {
+	tests := []struct {
+		pattern     string
+		expectError string
+	}{
+		{pattern: "!", expectError: "must not be empty"},
+		{pattern: "/src/**/*.go", expectError: "must be relative to the repository root"},
+		{pattern: "../**/*.go", expectError: "must not contain . or .. segments"},
+		{pattern: "src/**.go", expectError: "** must be a whole path segment"},
+		{pattern: "{src,lib/**}/*.go", expectError: "** must be a whole path segment"},
+		{pattern: "**/*.{yml,yaml", expectError: "unclosed {"},
+		{pattern: "**/*.yml}", expectError: "unmatched }"},
+		{pattern: "**/*.{yml}", expectError: "need at least two alternatives"},
+		{pattern: "**/*.go,**/*.py", expectError: "comma at offset 7 is outside braces"},
+		{pattern: "**/[a-z.go", expectError: "unclosed ["},
+		{pattern: "src/[z-a].go", expectError: "invalid character class [z-a] at offset 4"},
+		{pattern: "[[:alpha:]]", expectError: "invalid character class [[:alpha:] at offset 0"},
+		{pattern: `**/*.go\`, expectError: "must not end with an escape"},
+		{pattern: "{a,b}{c,d}{e,f}{g,h}{i,j}{k,l}{m,n}", expectError: "expands to 128 patterns"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			// when
+			_, err := parseGlob(tt.pattern)
+
+			// then
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("parseGlob(%q) error = %v, want error containing %q", tt.pattern, err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestGlobListMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		globs    GlobList
+		expected []string
+	}{
+		{
+			name:     "any depth",
+			globs:    GlobList{"**/*.go"},
+			expected: []string{"main.go", "cmd/app/main.go", "internal/user_mock.go"},
+		},
+		{
+			name:     "exclusion",
+			globs:    GlobList{"**/*.go", "!**/*_mock.go"},
+			expected: []string{"main.go", "cmd/app/main.go"},
+		},
+		{
+			name:     "braces and a directory exclusion",
+			globs:    GlobList{"**/*.{yml,yaml}", "!vendor/**"},
+			expected: []string{"config.yml", "deploy/values.yaml"},
+		},
+		{
+			name:     "single segment wildcard",
+			globs:    GlobList{"docs/*.md"},
+			expected: []string{"docs/Guide.md"},
+		},
+		{
+			name:     "escaped characters and classes",
+			globs:    GlobList{`a\,b.*`, `file\[[0-9]\].txt`},
+			expected: []string{"a,b.txt", "file[1].txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			var matched []string
+			for _, path := range globSamplePaths {
+				if tt.globs.matches(path) {
+					matched = append(matched, path)
+				}
+			}
+
+			// then
+			if strings.Join(matched, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("%q matched %q, want %q", tt.globs, matched, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGlobListTranslate(t *testing.T) {
+	// given
+	globs := GlobList{"web/**/*.{js,jsx,ts,tsx}", "!{vendor,dist}/**", `a\,b.*`}
+
+	// when
+	expanded := globs.translate(cursorGlobs)
+	kept := globs.translate(claudeGlobs)
+
+	// then
+	expected := "web/**/*.js|web/**/*.jsx|web/**/*.ts|web/**/*.tsx|!vendor/**|!dist/**|a\\,b.*"
+	if strings.Join(expanded, "|") != expected {
+		t.Errorf("translate(cursor) = %q, want %q", expanded, strings.Split(expected, "|"))
+	}
+	if strings.Join(kept, "|") != strings.Join(globs, "|") {
+		t.Errorf("translate(claude) = %q, want the patterns as written", kept)
+	}
+}
+
+func TestGlobTranslationsMatchTheSamePaths(t *testing.T) {
+	lists := []GlobList{
+		{"**/*.go", "!**/*_mock.go"},
+		{"**/*.{yml,yaml}", "!vendor/**"},
+		{"web/src/*.{ts,tsx,{js,jsx}}"},
+		{"{docs,web}/**/*.{md,ts}", "!docs/{drafts,archive}/**"},
+		{"**/[A-Z]*.md", "?????.go"},
+		{`a\,b.*`, `file\[[0-9]\].txt`},
+	}
+	dialects := map[string]globDialect{
+		"claude":   claudeGlobs,
+		"cursor":   cursorGlobs,
+		"copilot":  copilotGlobs,
+		"windsurf": windsurfGlobs,
+		"continue": continueGlobs,
+	}
+
+	for _, globs := range lists {
+		for name, dialect := range dialects {
+			t.Run(name+"/"+strings.Join(globs, " "), func(t *testing.T) {
+				// when
+				translated := globs.translate(dialect)
+
+				// then
+				if err := translated.validate("translated"); err != nil {
+					t.Fatalf("translated globs %q do not parse: %v", translated, err)
+				}
+				for _, pattern := range translated {
+					if !dialect.braces && strings.Contains(strings.ReplaceAll(pattern, `\{`, ""), "{") {
+						t.Errorf("pattern %q still uses braces", pattern)
+					}
+				}
+				for _, path := range globSamplePaths {
+					if globs.matches(path) != translated.matches(path) {
+						t.Errorf("%q and its translation %q disagree on %s", globs, translated, path)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestEmbeddedGlobsAreValid(t *testing.T) {
+	for language, profile := range languageProfiles {
+		if err := profile.Globs.validate(language); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GlobList is the list of file globs a rule group applies to. A pattern starting with "!"
+// excludes the files it matches, so "**/*.go" followed by "!**/*_mock.go" selects Go files
+// except generated mocks. An empty list means the group always applies.
+type GlobList []string
+
+// UnmarshalYAML accepts a single pattern as well as a list, so manifests and front matter
+// written before globs became a list keep working.
+func (g *GlobList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		var pattern string
+		if err := value.Decode(&pattern); err != nil {
+			return err
+		}
+		*g = GlobList{pattern}
+		return nil
+	}
+	var patterns []string
+	if err := value.Decode(&patterns); err != nil {
+		return err
+	}
+	*g = patterns
+	return nil
+}
+
+// MarshalYAML writes a single pattern as a scalar and several as a list, mirroring UnmarshalYAML.
+func (g GlobList) MarshalYAML() (any, error) {
+	if len(g) == 1 {
+		return g[0], nil
+	}
+	return []string(g), nil
+}
+
+// includes returns the patterns selecting files.
+func (g GlobList) includes() []string {
+	var patterns []string
+	for _, pattern := range g {
+		if !strings.HasPrefix(pattern, "!") {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// excludes returns the negated patterns, without their "!".
+func (g GlobList) excludes() []string {
+	var patterns []string
+	for _, pattern := range g {
+		if negated, ok := strings.CutPrefix(pattern, "!"); ok {
+			patterns = append(patterns, negated)
+		}
+	}
+	return patterns
+}
+
+// validate reports patterns that do not parse (see parseGlob) and lists made only of
+// exclusions, which would match nothing. field prefixes each error, e.g. "groups[0]".
+func (g GlobList) validate(field string) error {
+	var errs []error
+	for i, pattern := range g {
+		if _, err := parseGlob(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("%s.globs[%d] %q: %w", field, i, pattern, err))
+		}
+	}
+	if len(g) > 0 && len(g.includes()) == 0 {
+		errs = append(errs, fmt.Errorf("%s.globs must include at least one pattern that is not negated", field))
+	}
+	return errors.Join(errs...)
+}
+
+// describe returns the patterns as shown in notices and indexes,
+// e.g. "`**/*.go`, excluding `**/*_mock.go`".
+func (g GlobList) describe() string {
+	quote := func(patterns []string) string {
+		return "`" + strings.Join(patterns, "`, `") + "`"
+	}
+	described := quote(g.includes())
+	if excludes := g.excludes(); len(excludes) > 0 {
+		described += ", excluding " + quote(excludes)
+	}
+	return described
+}
+
+// directory returns the directory shared by every included pattern (see globDirectory),
+// or an empty string when they can match in different places.
+func (g GlobList) directory() string {
+	includes := g.includes()
+	if len(includes) == 0 {
+		return ""
+	}
+	dir := globDirectory(includes[0])
+	for _, pattern := range includes[1:] {
+		if globDirectory(pattern) != dir {
+			return ""
+		}
+	}
+	return dir
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestGlobListUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected GlobList
+	}{
+		{
+			name:     "single pattern",
+			input:    "globs: '**/*.go'\n",
+			expected: GlobList{"**/*.go"},
+		},
+		{
+			name:     "list of patterns",
+			input:    "globs:\n  - '**/*.go'\n  - '!**/*_mock.go'\n",
+			expected: GlobList{"**/*.go", "!**/*_mock.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			var decoded struct {
+				Globs GlobList `yaml:"globs"`
+			}
+
+			// when
+			err := yaml.Unmarshal([]byte(tt.input), &decoded)
+
+			// then
+			if err != nil {
+				t.Fatalf("yaml.Unmarshal() error: %v", err)
+			}
+			if strings.Join(decoded.Globs, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("decoded globs = %q, want %q", decoded.Globs, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGlobListDescribe(t *testing.T) {
+	// given
+	globs := GlobList{"**/*.yaml", "**/*.yml", "!vendor/**"}
+
+	// when
+	result := globs.describe()
+
+	// then
+	if result != "`**/*.yaml`, `**/*.yml`, excluding `vendor/**`" {
+		t.Errorf("describe() = %q", result)
+	}
+}
+
+func TestGlobListDirectory(t *testing.T) {
+	tests := []struct {
+		name     string
+		globs    GlobList
+		expected string
+	}{
+		{name: "shared directory", globs: GlobList{"docs/**/*.md", "docs/*.txt", "!docs/drafts/**"}, expected: "docs"},
+		{name: "different directories", globs: GlobList{"docs/**/*.md", "site/**/*.md"}, expected: ""},
+		{name: "always apply", globs: nil, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			result := tt.globs.directory()
+
+			// then
+			if result != tt.expected {
+				t.Errorf("directory() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
@@ -2,6 +2,11 @@ module github.com/rios0rios0/guide/generate-ai-rules
 
 go 1.26.2
 
-require github.com/sirupsen/logrus v1.9.4
+require (
+	github.com/pmezard/go-difflib v1.0.0
+	github.com/sirupsen/logrus v1.9.4
+	github.com/yuin/goldmark v1.8.2
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require golang.org/x/sys v0.41.0 // indirect
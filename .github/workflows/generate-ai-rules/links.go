@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// defaultWikiURL is the wiki where pages outside every rule group are published by update-wiki.
+const defaultWikiURL = "https://github.com/rios0rios0/guide/wiki"
+
+// linkResolver rewrites links to internal pages. Links to a page of another rule group
+// become a reference to that group's rule, in the syntax of the target being rendered;
+// links to pages that no group includes become absolute wiki URLs.
+type linkResolver struct {
+	owners  map[string]RuleGroup // source path -> group that includes it
+	wikiURL string               // empty to reduce such links to their display text
+}
+
+// newLinkResolver indexes the sources of every group. A page listed by several groups
+// resolves to the first one.
+func newLinkResolver(groups []RuleGroup, wikiURL string) linkResolver {
+	owners := make(map[string]RuleGroup)
+	for _, group := range groups {
+		for _, src := range group.Sources {
+			if _, ok := owners[src]; !ok {
+				owners[src] = group
+			}
+		}
+	}
+	return linkResolver{owners: owners, wikiURL: strings.TrimRight(wikiURL, "/")}
+}
+
+// visitor returns the visitor resolving the links of the page at pagePath, which is a
+// source of group, as they should read in the rules of target.
+func (r linkResolver) visitor(pagePath string, group RuleGroup, target Target) visitor {
+	return func(node ast.Node, source []byte) []edit {
+		link, ok := node.(*ast.Link)
+		if !ok || !isInternalPage(link.Destination) {
+			return nil
+		}
+		page, anchor, _ := bytes.Cut(link.Destination, []byte("#"))
+		resolved := path.Clean(path.Join(path.Dir(pagePath), string(page)))
+
+		opening, closing := link.Pos(), labelEnd(source, link.Pos())
+		end := linkEnd(source, closing)
+		owner, owned := r.owners[resolved]
+		switch {
+		case owned && owner.Name != group.Name:
+			return []edit{
+				{Start: opening, Stop: opening + 1},
+				{Start: closing, Stop: end, Text: " (" + target.Reference(owner) + ")"},
+			}
+		case !owned && r.wikiURL != "" && !strings.HasPrefix(resolved, "../"):
+			destination := r.wikiURL + "/" + url.PathEscape(strings.TrimSuffix(path.Base(resolved), ".md"))
+			if len(anchor) > 0 {
+				destination += "#" + string(anchor)
+			}
+			return []edit{{Start: closing, Stop: end, Text: "](" + destination + ")"}}
+		}
+		return transformLinks(node, source)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLinkResolver(t *testing.T) {
+	groups := []RuleGroup{
+		{Name: "golang", Description: "Go", Sources: []string{"Code-Style/GoLang.md", "Code-Style/GoLang/GoLang-Testing.md"}},
+		{Name: "git-flow", Description: "Git workflow", Sources: []string{"Life-Cycle/Git-Flow.md"}},
+	}
+	tests := []struct {
+		name     string
+		target   Target
+		wikiURL  string
+		input    string
+		expected string
+	}{
+		{
+			name:     "link to another group becomes a Claude file reference",
+			target:   claudeTarget{},
+			wikiURL:  defaultWikiURL,
+			input:    "Follow [Git Flow](../../Life-Cycle/Git-Flow.md).\n",
+			expected: "Follow Git Flow (@claude/rules/git-flow.md).\n",
+		},
+		{
+			name:     "link to another group becomes a Cursor rule mention",
+			target:   cursorTarget{},
+			wikiURL:  defaultWikiURL,
+			input:    "Follow [Git Flow](../../Life-Cycle/Git-Flow.md#branches).\n",
+			expected: "Follow Git Flow (@git-flow).\n",
+		},
+		{
+			name:     "link to another group becomes a Copilot pointer",
+			target:   copilotTarget{},
+			wikiURL:  defaultWikiURL,
+			input:    "Follow [Git Flow](../../Life-Cycle/Git-Flow.md).\n",
+			expected: "Follow Git Flow (see the git-flow instructions).\n",
+		},
+		{
+			name:     "link within the same group keeps only the text",
+			target:   claudeTarget{},
+			wikiURL:  defaultWikiURL,
+			input:    "See [Go](../GoLang.md).\n",
+			expected: "See Go.\n",
+		},
+		{
+			name:     "link outside every group becomes a wiki URL",
+			target:   claudeTarget{},
+			wikiURL:  defaultWikiURL,
+			input:    "See the [vocabulary](../../Code-Style.md#operations-vocabulary).\n",
+			expected: "See the [vocabulary](https://github.com/rios0rios0/guide/wiki/Code-Style#operations-vocabulary).\n",
+		},
+		{
+			name:     "special characters in wiki page names are escaped",
+			target:   claudeTarget{},
+			wikiURL:  defaultWikiURL + "/",
+			input:    "See [docs](../../Life-Cycle/Documentation-&-Change-Control.md).\n",
+			expected: "See [docs](https://github.com/rios0rios0/guide/wiki/Documentation-&-Change-Control).\n",
+		},
+		{
+			name:     "without a wiki URL outside links keep only the text",
+			target:   claudeTarget{},
+			input:    "See the [vocabulary](../../Code-Style.md).\n",
+			expected: "See the vocabulary.\n",
+		},
+		{
+			name:     "external links unchanged",
+			target:   claudeTarget{},
+			wikiURL:  defaultWikiURL,
+			input:    "See [Effective Go](https://go.dev/doc/effective_go).\n",
+			expected: "See [Effective Go](https://go.dev/doc/effective_go).\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			resolver := newLinkResolver(groups, tt.wikiURL)
+			visit := resolver.visitor("Code-Style/GoLang/GoLang-Testing.md", groups[0], tt.target)
+
+			// when
+			result := parsePage(tt.input).render(visit)
+
+			// then
+			if result != tt.expected {
+				t.Errorf("resolved links\n  got:  %q\n  want: %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTargetReferencesNameTheGroup(t *testing.T) {
+	// given
+	group := RuleGroup{Name: "git-flow", Description: "Git workflow, branching, and commit conventions"}
+
+	for _, target := range targets() {
+		t.Run(target.Name(), func(t *testing.T) {
+			// when
+			reference := target.Reference(group)
+
+			// then
+			if !strings.Contains(reference, group.Name) && !strings.Contains(reference, group.Description) {
+				t.Errorf("Reference() = %q, want it to name the git-flow group", reference)
+			}
+		})
+	}
+}
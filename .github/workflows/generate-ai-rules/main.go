@@ -1,17 +1,37 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	logger "github.com/sirupsen/logrus"
 )
 
+// Exit codes. In -dry-run and -diff modes, exitChanged reports that the rendered
+// output differs from the compared tree, mirroring how diff(1) signals differences;
+// in -verify mode, it reports generated files that were edited by hand.
+const (
+	exitErrors  = 1
+	exitChanged = 2
+)
+
 func main() {
 	sourceDir := flag.String("source", ".", "root directory of the documentation repository")
 	outputDir := flag.String("output", ".", "directory where generated rule files are written")
+	configPath := flag.String("config", "", "path to a YAML/JSON rule group manifest; defaults to the embedded rule groups")
+	dryRun := flag.Bool("dry-run", false, "render in memory and print a unified diff against -output without writing")
+	diffDir := flag.String("diff", "", "render in memory and print a unified diff against a previously generated tree")
+	targetList := flag.String("targets", allTargets, "comma-separated output targets to emit, or \"all\"")
+	strict := flag.Bool("strict", false, "count missing or unreadable source files as errors")
+	reportPath := flag.String("report", "", "write a size and token budget report to this path (.json or .md)")
+	allowHTML := flag.String("allow-html", defaultAllowedHTML, "comma-separated HTML elements kept in the rules; other HTML is removed or flattened to its text")
+	wikiURL := flag.String("wiki-url", defaultWikiURL, "base URL for links to pages outside every rule group; empty keeps only the link text")
+	verifyDir := flag.String("verify", "", "check the provenance of generated files below this directory, e.g. a repository the rules were installed into, and report hand-edited files")
 	logLevel := flag.String("log-level", "info", "log level: trace, debug, info, warn, error, fatal")
 	flag.Parse()
 
@@ -23,21 +43,86 @@ func main() {
 	}
 	logger.SetLevel(level)
 
-	groups := ruleGroups()
+	if *verifyDir != "" {
+		os.Exit(verify(*verifyDir))
+	}
+
+	selected, err := selectTargets(*targetList)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"targets": *targetList,
+			"error":   err.Error(),
+		}).Fatal("invalid target selection")
+	}
+
+	var groups []RuleGroup
+	if *configPath != "" {
+		groups, err = loadManifest(*configPath)
+	} else {
+		groups, err = ruleGroups()
+	}
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"config": *configPath,
+			"error":  err.Error(),
+		}).Fatal("invalid rule group manifest")
+	}
+
+	discovered, warnings, err := discoverLanguageGroups(*sourceDir)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"source_dir": *sourceDir,
+			"error":      err.Error(),
+		}).Fatal("failed to discover language guides")
+	}
+	logDiscoveryWarnings(warnings)
+	groups = mergeGroups(discovered, groups)
+
+	metas, err := scanPageMeta(*sourceDir)
+	if err == nil {
+		groups, err = applyPageMeta(groups, metas)
+	}
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"source_dir": *sourceDir,
+			"error":      err.Error(),
+		}).Fatal("invalid page front matter")
+	}
+	// discovered groups and page front matter are held to the same rules as the manifest
+	if err := validateGroups(groups); err != nil {
+		logger.WithFields(logger.Fields{
+			"source_dir": *sourceDir,
+			"error":      err.Error(),
+		}).Fatal("invalid rule groups after discovery and page front matter")
+	}
 
 	logger.WithFields(logger.Fields{
 		"source_dir":  *sourceDir,
 		"output_dir":  *outputDir,
+		"config":      *configPath,
+		"strict":      *strict,
+		"report":      *reportPath,
+		"wiki_url":    *wikiURL,
+		"allow_html":  *allowHTML,
+		"targets":     targetNames(selected),
 		"log_level":   *logLevel,
 		"group_count": len(groups),
+		"discovered":  len(discovered),
+		"page_meta":   len(metas),
 	}).Info("starting rule generation")
 
 	start := time.Now()
-	contents := make([]string, len(groups))
+	contents := make(ruleContents, len(selected))
+	for _, target := range selected {
+		contents[target.Name()] = make([]string, len(groups))
+	}
+	resolver := newLinkResolver(groups, *wikiURL)
+	sanitizer := newHTMLSanitizer(*allowHTML)
+	missing := make(map[string][]string)
 	var errorCount int
 
 	for i, group := range groups {
-		merged, err := processGroup(*sourceDir, group)
+		merged, missingSources, err := processGroup(*sourceDir, group, selected, resolver, sanitizer)
 		if err != nil {
 			logger.WithFields(logger.Fields{
 				"group": group.Name,
@@ -46,26 +131,116 @@ func main() {
 			errorCount++
 			continue
 		}
-		contents[i] = merged
+		for name, content := range merged {
+			contents[name][i] = content
+		}
+		if len(missingSources) > 0 {
+			missing[group.Name] = missingSources
+			if *strict {
+				errorCount += len(missingSources)
+			}
+		}
+	}
+
+	groups, contents = applyRenderModes(selected, groups, contents)
+	files, renderErrs := renderAllRules(selected, groups, contents)
+	errorCount += countRenderErrors(renderErrs, *strict)
+	files = stampProvenance(files, newSourceCommits(*sourceDir))
+
+	report := buildReport(selected, groups, contents, files)
+	errorCount += checkTokenBudgets(report)
+	if *reportPath != "" {
+		if err := writeReport(*reportPath, report); err != nil {
+			logger.WithFields(logger.Fields{
+				"report": *reportPath,
+				"error":  err.Error(),
+			}).Error("failed to write budget report")
+			errorCount++
+		}
 	}
 
-	writeErrors := writeAllRules(*outputDir, groups, contents)
+	var writeErrors int
+	var changed bool
+	if *dryRun || *diffDir != "" {
+		baseDir := *outputDir
+		if *diffDir != "" {
+			baseDir = *diffDir
+		}
+		changed, err = previewRules(os.Stdout, baseDir, targetPaths(selected), files)
+		if err != nil {
+			logger.WithFields(logger.Fields{
+				"base_dir": baseDir,
+				"error":    err.Error(),
+			}).Error("failed to diff generated rules")
+			writeErrors++
+		}
+	} else {
+		writeErrors = writeAllRules(*outputDir, selected, files)
+	}
+	logMissingSources(groups, missing, *strict)
 	totalErrors := errorCount + writeErrors
 
 	logger.WithFields(logger.Fields{
 		"groups_processed": len(groups),
 		"errors":           totalErrors,
+		"changed":          changed,
 		"duration":         time.Since(start).String(),
 	}).Info("rule generation complete")
 
 	if totalErrors > 0 {
-		os.Exit(1)
+		os.Exit(exitErrors)
+	}
+	if changed {
+		os.Exit(exitChanged)
+	}
+}
+
+// verify checks the generated files below dir against their provenance blocks, logs the
+// files edited by hand, and returns the exit code of the -verify mode.
+func verify(dir string) int {
+	checked, edited, err := verifyGeneratedFiles(dir)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"dir":   dir,
+			"error": err.Error(),
+		}).Error("failed to verify generated rules")
+		return exitErrors
+	}
+
+	paths := make([]string, 0, len(edited))
+	for path := range edited {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		logger.WithFields(logger.Fields{
+			"path":   path,
+			"reason": edited[path].Error(),
+		}).Warn("generated rule file was edited by hand")
 	}
+	logger.WithFields(logger.Fields{
+		"dir":     dir,
+		"checked": checked,
+		"edited":  len(edited),
+	}).Info("rule verification complete")
+
+	if len(edited) > 0 {
+		return exitChanged
+	}
+	return 0
 }
 
-// processGroup reads and transforms all source files for a rule group.
-func processGroup(sourceDir string, group RuleGroup) (string, error) {
-	var parts []string
+// processGroup reads and transforms all source files for a rule group, and returns
+// the merged content for each selected target, keyed by target name. Every source is
+// parsed once and sanitized once; only target blocks and internal links are resolved per target.
+// Footnotes and references are cited as the group's citation mode asks.
+// Sources that cannot be read are skipped and returned separately, so the caller
+// can decide whether a partially merged group is acceptable.
+func processGroup(sourceDir string, group RuleGroup, selected []Target, resolver linkResolver, sanitizer htmlSanitizer) (map[string]string, []string, error) {
+	var pages []page
+	var paths []string
+	var missing []string
+	var cited []citation
 	for _, src := range group.Sources {
 		path := filepath.Join(sourceDir, src)
 		data, err := os.ReadFile(path)
@@ -75,81 +250,172 @@ func processGroup(sourceDir string, group RuleGroup) (string, error) {
 				"source": src,
 				"error":  err.Error(),
 			}).Warn("skipped source file")
+			missing = append(missing, src)
 			continue
 		}
-		transformed := transformContent(string(data))
+		_, body := splitFrontMatter(data)
+		sidecars := newDiagramSidecars(sourceDir, src)
+		parsed := parsePage(string(body), sanitizer.visit, sidecars.visit)
+		if len(sidecars.missing) > 0 {
+			logger.WithFields(logger.Fields{
+				"group":  group.Name,
+				"source": src,
+				"images": sidecars.missing,
+			}).Warn("images have no diagram sidecar and are left out of the rules")
+		}
 		logger.WithFields(logger.Fields{
-			"group":        group.Name,
-			"source":       src,
-			"raw_bytes":    len(data),
-			"output_bytes": len(transformed),
+			"group":     group.Name,
+			"source":    src,
+			"raw_bytes": len(data),
+			"edits":     len(parsed.edits),
 		}).Debug("processed source file")
-		parts = append(parts, transformed)
+		pages = append(pages, parsed)
+		paths = append(paths, src)
+		if group.Citations == citationsSources {
+			cited = appendCitations(cited, parsed.citations())
+		}
 	}
-	return mergeContents(parts), nil
-}
 
-// writeAllRules writes rule files for all AI assistants (Claude, Cursor, Copilot, and Codex).
-// It returns the number of errors encountered during writing.
-func writeAllRules(outputDir string, groups []RuleGroup, contents []string) int {
-	var errorCount int
-	var claudeCount, cursorCount, copilotCount int
+	merged := make(map[string]string, len(selected))
+	for _, target := range selected {
+		parts := make([]string, len(pages))
+		for i, p := range pages {
+			parts[i] = p.render(
+				selectTargetBlocks(target.Name()),
+				resolver.visitor(paths[i], group, target),
+				footnoteVisitor(group.Citations),
+			)
+		}
+		content := mergeContents(group, parts)
+		if sources := formatSources(cited); content != "" && sources != "" {
+			content += "\n" + sources
+		}
+		merged[target.Name()] = content
+	}
+	return merged, missing, nil
+}
 
-	for i, group := range groups {
-		if contents[i] == "" {
-			logger.WithFields(logger.Fields{
-				"group": group.Name,
-			}).Debug("skipped group with empty content")
+// logMissingSources prints a per-group summary of the source files that could not be read.
+// In strict mode the summary is logged as errors, since those sources fail the run.
+func logMissingSources(groups []RuleGroup, missing map[string][]string, strict bool) {
+	level := logger.WarnLevel
+	if strict {
+		level = logger.ErrorLevel
+	}
+	for _, group := range groups {
+		sources, ok := missing[group.Name]
+		if !ok {
 			continue
 		}
-		if err := writeClaude(outputDir, group, contents[i]); err != nil {
-			logger.WithFields(logger.Fields{
-				"group": group.Name,
-				"error": err.Error(),
-			}).Error("failed to write Claude rule")
-			errorCount++
-		} else {
-			claudeCount++
+		logger.WithFields(logger.Fields{
+			"group":           group.Name,
+			"missing_count":   len(sources),
+			"missing_sources": sources,
+		}).Log(level, "group published without some of its sources")
+	}
+}
+
+// renderAllRules renders the rule files of every selected target in memory,
+// without touching the filesystem. Errors from aggregate rendering are collected
+// rather than aborting, so the remaining targets are still rendered. Files rendered
+// for a single group list the group's sources unless the target set them itself.
+func renderAllRules(selected []Target, groups []RuleGroup, contents ruleContents) ([]renderedFile, []error) {
+	var files []renderedFile
+	var errs []error
+	for _, target := range selected {
+		targetContents := contents[target.Name()]
+		for i, group := range groups {
+			if targetContents[i] == "" {
+				logger.WithFields(logger.Fields{
+					"target": target.Name(),
+					"group":  group.Name,
+				}).Debug("skipped group with empty content")
+				continue
+			}
+			for _, file := range target.RenderGroup(group, targetContents[i]) {
+				if file.Sources == nil {
+					file.Sources = group.Sources
+				}
+				files = append(files, file)
+			}
 		}
-		if err := writeCursor(outputDir, group, contents[i]); err != nil {
-			logger.WithFields(logger.Fields{
-				"group": group.Name,
-				"error": err.Error(),
-			}).Error("failed to write Cursor rule")
-			errorCount++
-		} else {
-			cursorCount++
+		aggregate, err := target.RenderAggregate(groups, targetContents)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", target.Name(), err))
 		}
-		if err := writeCopilot(outputDir, group, contents[i]); err != nil {
+		files = append(files, aggregate...)
+	}
+	return files, errs
+}
+
+// countRenderErrors logs rendering errors and returns how many of them fail the run.
+// Size budget violations only count in strict mode.
+func countRenderErrors(errs []error, strict bool) int {
+	var count int
+	for _, err := range errs {
+		if errors.Is(err, errBudgetExceeded) && !strict {
 			logger.WithFields(logger.Fields{
-				"group": group.Name,
 				"error": err.Error(),
-			}).Error("failed to write Copilot instruction")
-			errorCount++
-		} else {
-			copilotCount++
+			}).Warn("rendered rules exceed a size budget")
+			continue
 		}
-	}
-
-	if err := writeCodex(outputDir, groups, contents); err != nil {
 		logger.WithFields(logger.Fields{
 			"error": err.Error(),
-		}).Error("failed to write Codex AGENTS.md")
-		errorCount++
+		}).Error("failed to render rules")
+		count++
 	}
+	return count
+}
 
-	if err := writeCodexRules(outputDir); err != nil {
+// writeAllRules writes the rendered rule files of every selected target and removes the
+// files under the paths of those targets that are no longer rendered, such as the parts
+// of a group that now fits in fewer files, so the tree matches what -dry-run reports.
+// It returns the number of errors encountered during writing.
+func writeAllRules(outputDir string, selected []Target, files []renderedFile) int {
+	var errorCount int
+	counts := make(map[string]int)
+
+	existing, err := readOwnedFiles(outputDir, targetPaths(selected))
+	if err != nil {
 		logger.WithFields(logger.Fields{
-			"error": err.Error(),
-		}).Error("failed to write Codex rules")
+			"output_dir": outputDir,
+			"error":      err.Error(),
+		}).Error("failed to read previously generated rules")
 		errorCount++
 	}
 
-	logger.WithFields(logger.Fields{
-		"claude_rules":        claudeCount,
-		"cursor_rules":        cursorCount,
-		"copilot_instructions": copilotCount,
-	}).Info("completed writing rules")
+	for _, file := range files {
+		delete(existing, file.Path)
+		if err := writeRenderedFile(outputDir, file); err != nil {
+			logger.WithFields(logger.Fields{
+				"target": file.Target,
+				"path":   file.Path,
+				"error":  err.Error(),
+			}).Error("failed to write rule file")
+			errorCount++
+			continue
+		}
+		counts[file.Target]++
+	}
+
+	var removed int
+	for stale := range existing {
+		if err := removeRenderedFile(outputDir, stale); err != nil {
+			logger.WithFields(logger.Fields{
+				"path":  stale,
+				"error": err.Error(),
+			}).Error("failed to remove stale rule file")
+			errorCount++
+			continue
+		}
+		removed++
+	}
+
+	fields := logger.Fields{"removed_files": removed}
+	for _, target := range selected {
+		fields[target.Name()+"_files"] = counts[target.Name()]
+	}
+	logger.WithFields(fields).Info("completed writing rules")
 
 	return errorCount
 }
@@ -55,16 +55,20 @@ Use snake_case.
 			"Code-Style/GoLang.md",
 			"Code-Style/GoLang/GoLang-Conventions.md",
 		},
-		Globs: "**/*.go",
+		Globs: GlobList{"**/*.go"},
 	}
 
 	// when
-	result, err := processGroup(tmpDir, group)
+	merged, missing, err := processGroup(tmpDir, group, []Target{claudeTarget{}}, newLinkResolver(nil, ""), newHTMLSanitizer(defaultAllowedHTML))
+	result := merged["claude"]
 
 	// then
 	if err != nil {
 		t.Fatalf("processGroup() error: %v", err)
 	}
+	if len(missing) != 0 {
+		t.Errorf("processGroup() reported missing sources: %v", missing)
+	}
 	if !strings.Contains(result, "Go Proverbs") {
 		t.Error("result should contain Go Proverbs section")
 	}
@@ -82,13 +86,41 @@ Use snake_case.
 	}
 }
 
+func TestProcessGroupMissingSources(t *testing.T) {
+	// given
+	tmpDir := t.TempDir()
+	writeTestFile(t, tmpDir, "Life-Cycle/Git-Flow.md", "# Git Flow\n\nUse feature branches.\n")
+	group := RuleGroup{
+		Name: "git-flow",
+		Sources: []string{
+			"Life-Cycle/Git-Flow.md",
+			"Life-Cycle/Git-Flow/Renamed-Guide.md",
+		},
+	}
+
+	// when
+	merged, missing, err := processGroup(tmpDir, group, []Target{claudeTarget{}}, newLinkResolver(nil, ""), newHTMLSanitizer(defaultAllowedHTML))
+	result := merged["claude"]
+
+	// then
+	if err != nil {
+		t.Fatalf("processGroup() error: %v", err)
+	}
+	if !strings.Contains(result, "feature branches") {
+		t.Error("result should still contain the readable source")
+	}
+	if len(missing) != 1 || missing[0] != "Life-Cycle/Git-Flow/Renamed-Guide.md" {
+		t.Errorf("processGroup() missing = %v, want the renamed guide", missing)
+	}
+}
+
 func TestEndToEnd(t *testing.T) {
 	// given
 	sourceDir := t.TempDir()
 	outputDir := t.TempDir()
 
 	// create minimal source files for a few rule groups
-	writeTestFile(t, sourceDir, "Code-Style.md", "# Code Style\n\nNaming conventions.\n")
+	writeTestFile(t, sourceDir, "Code-Style.md", "# Code Style\n\nNaming conventions.\n\n<!-- target:claude -->\nRun the changelog-guard hook.\n<!-- /target -->\n")
 	writeTestFile(t, sourceDir, "Life-Cycle/Git-Flow.md", "# Git Flow\n\nUse feature branches.\n\n## References\n\n- [SemVer](https://semver.org)\n")
 	writeTestFile(t, sourceDir, "Life-Cycle/Git-Flow/Merge-Guide.md", "# Merge Guide\n\nRebase before merge.\n")
 
@@ -106,17 +138,27 @@ func TestEndToEnd(t *testing.T) {
 		},
 	}
 
-	contents := make([]string, len(groups))
+	contents := make(ruleContents)
+	for _, target := range targets() {
+		contents[target.Name()] = make([]string, len(groups))
+	}
+	resolver := newLinkResolver(groups, defaultWikiURL)
 	for i, group := range groups {
-		merged, err := processGroup(sourceDir, group)
+		merged, _, err := processGroup(sourceDir, group, targets(), resolver, newHTMLSanitizer(defaultAllowedHTML))
 		if err != nil {
 			t.Fatalf("processGroup(%q) error: %v", group.Name, err)
 		}
-		contents[i] = merged
+		for name, content := range merged {
+			contents[name][i] = content
+		}
 	}
 
 	// when
-	errCount := writeAllRules(outputDir, groups, contents)
+	files, renderErrs := renderAllRules(targets(), groups, contents)
+	if len(renderErrs) != 0 {
+		t.Errorf("renderAllRules reported errors: %v", renderErrs)
+	}
+	errCount := writeAllRules(outputDir, targets(), files)
 	if errCount != 0 {
 		t.Errorf("writeAllRules reported %d errors", errCount)
 	}
@@ -125,6 +167,7 @@ func TestEndToEnd(t *testing.T) {
 	claudeCodeStyle := filepath.Join(outputDir, "claude", "rules", "code-style.md")
 	assertFileExists(t, claudeCodeStyle)
 	assertFileContains(t, claudeCodeStyle, "Naming conventions")
+	assertFileContains(t, claudeCodeStyle, "changelog-guard")
 	assertFileNotContains(t, claudeCodeStyle, "---\npaths:")
 
 	claudeGitFlow := filepath.Join(outputDir, "claude", "rules", "git-flow.md")
@@ -137,6 +180,7 @@ func TestEndToEnd(t *testing.T) {
 	cursorCodeStyle := filepath.Join(outputDir, "cursor", "rules", "code-style.mdc")
 	assertFileExists(t, cursorCodeStyle)
 	assertFileContains(t, cursorCodeStyle, "alwaysApply: true")
+	assertFileNotContains(t, cursorCodeStyle, "changelog-guard")
 
 	cursorGitFlow := filepath.Join(outputDir, "cursor", "rules", "git-flow.mdc")
 	assertFileExists(t, cursorGitFlow)
@@ -169,6 +213,91 @@ func TestEndToEnd(t *testing.T) {
 	assertFileContains(t, codexRulesFile, "prompt")
 }
 
+func TestRepositoryRulesFitBudgets(t *testing.T) {
+	// given
+	repoRoot := filepath.Join("..", "..", "..")
+	selected, err := selectTargets(allTargets)
+	if err != nil {
+		t.Fatalf("selectTargets() error: %v", err)
+	}
+	groups, err := loadManifest("rule-groups.yaml")
+	if err != nil {
+		t.Fatalf("loadManifest() error: %v", err)
+	}
+	discovered, _, err := discoverLanguageGroups(repoRoot)
+	if err != nil {
+		t.Fatalf("discoverLanguageGroups() error: %v", err)
+	}
+	metas, err := scanPageMeta(repoRoot)
+	if err != nil {
+		t.Fatalf("scanPageMeta() error: %v", err)
+	}
+	groups, err = applyPageMeta(mergeGroups(discovered, groups), metas)
+	if err != nil {
+		t.Fatalf("applyPageMeta() error: %v", err)
+	}
+	contents := make(ruleContents, len(selected))
+	for _, target := range selected {
+		contents[target.Name()] = make([]string, len(groups))
+	}
+	resolver := newLinkResolver(groups, defaultWikiURL)
+	sanitizer := newHTMLSanitizer(defaultAllowedHTML)
+	for i, group := range groups {
+		merged, missing, err := processGroup(repoRoot, group, selected, resolver, sanitizer)
+		if err != nil || len(missing) > 0 {
+			t.Fatalf("processGroup(%s) error = %v, missing = %v", group.Name, err, missing)
+		}
+		for name, content := range merged {
+			contents[name][i] = content
+		}
+	}
+
+	// when
+	groups, contents = applyRenderModes(selected, groups, contents)
+	files, errs := renderAllRules(selected, groups, contents)
+	report := buildReport(selected, groups, contents, files)
+
+	// then
+	// the publish workflow runs with -strict, so these would fail it
+	if count := countRenderErrors(errs, true); count > 0 {
+		t.Errorf("rendering the repository rules reported %d errors in strict mode: %v", count, errs)
+	}
+	if count := checkTokenBudgets(report); count > 0 {
+		t.Errorf("%d groups of the repository exceed their token_budget", count)
+	}
+}
+
+func TestWriteAllRulesRemovesStaleFiles(t *testing.T) {
+	// given
+	outputDir := t.TempDir()
+	writeTestFile(t, outputDir, "windsurf/rules/golang-2.md", "# Old part\n")
+	writeTestFile(t, outputDir, "claude/skills/old-skill/SKILL.md", "# Old skill\n")
+	writeTestFile(t, outputDir, "README.md", "# Not owned\n")
+	files := []renderedFile{
+		{Target: "windsurf", Path: "windsurf/rules/golang.md", Body: "# Go\n"},
+		{Target: "claude", Path: "claude/rules/golang.md", Body: "# Go\n"},
+	}
+
+	// when
+	errCount := writeAllRules(outputDir, targets(), files)
+
+	// then
+	if errCount != 0 {
+		t.Errorf("writeAllRules reported %d errors", errCount)
+	}
+	for _, stale := range []string{"windsurf/rules/golang-2.md", "claude/skills/old-skill"} {
+		if _, err := os.Stat(filepath.Join(outputDir, stale)); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed, stat error: %v", stale, err)
+		}
+	}
+	assertFileExists(t, filepath.Join(outputDir, "README.md"))
+	var out strings.Builder
+	changed, err := previewRules(&out, outputDir, targetPaths(targets()), files)
+	if err != nil || changed {
+		t.Errorf("previewRules() after writing = %v, %v, want no changes\n%s", changed, err, out.String())
+	}
+}
+
 func writeTestFile(t *testing.T, baseDir, relPath, content string) {
 	t.Helper()
 	fullPath := filepath.Join(baseDir, relPath)
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// manifestVersion is the only manifest schema version understood by this generator.
+const manifestVersion = 1
+
+// groupNameRegex restricts group names to lowercase kebab-case, since they become output filenames.
+var groupNameRegex = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Manifest is the versioned, declarative form of the rule group definitions.
+// Since JSON is a subset of YAML, the same loader accepts both formats.
+type Manifest struct {
+	Version int         `yaml:"version"`
+	Groups  []RuleGroup `yaml:"groups"`
+}
+
+// loadManifest reads, decodes, and validates the rule group manifest at path.
+// Unknown keys are rejected so that typos fail loudly instead of being ignored.
+func loadManifest(path string) ([]RuleGroup, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest %s: %w", path, err)
+	}
+	return decodeManifest(data, path)
+}
+
+// decodeManifest parses and validates manifest bytes, naming the manifest in errors.
+func decodeManifest(data []byte, name string) ([]RuleGroup, error) {
+	manifest, err := parseManifest(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing manifest %s: %w", name, err)
+	}
+	if err := validateManifest(manifest); err != nil {
+		return nil, fmt.Errorf("validating manifest %s: %w", name, err)
+	}
+	return manifest.Groups, nil
+}
+
+// parseManifest decodes manifest bytes in strict mode.
+func parseManifest(data []byte) (Manifest, error) {
+	var manifest Manifest
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&manifest); err != nil {
+		return Manifest{}, err
+	}
+	return manifest, nil
+}
+
+// validateManifest checks a decoded manifest against the schema rules that
+// the type system alone cannot express. All violations are reported at once.
+func validateManifest(manifest Manifest) error {
+	var errs []error
+	if manifest.Version != manifestVersion {
+		errs = append(errs, fmt.Errorf("unsupported version %d (expected %d)", manifest.Version, manifestVersion))
+	}
+	if len(manifest.Groups) == 0 {
+		errs = append(errs, errors.New("at least one group is required"))
+	}
+	if err := validateGroups(manifest.Groups); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// validateGroups checks rule groups against the schema rules, wherever they were declared:
+// in a manifest, by discovery, or in page front matter. All violations are reported at once.
+func validateGroups(groups []RuleGroup) error {
+	var errs []error
+	seen := make(map[string]bool, len(groups))
+	for i, group := range groups {
+		field := fmt.Sprintf("groups[%d]", i)
+		if !groupNameRegex.MatchString(group.Name) {
+			errs = append(errs, fmt.Errorf("%s.name %q must be lowercase kebab-case", field, group.Name))
+		} else if seen[group.Name] {
+			errs = append(errs, fmt.Errorf("%s.name %q is declared more than once", field, group.Name))
+		}
+		seen[group.Name] = true
+
+		if strings.TrimSpace(group.Description) == "" {
+			errs = append(errs, fmt.Errorf("%s.description is required", field))
+		}
+		if group.TokenBudget < 0 {
+			errs = append(errs, fmt.Errorf("%s.token_budget must not be negative", field))
+		}
+		if group.Citations != "" && !slices.Contains(citationModes, group.Citations) {
+			errs = append(errs, fmt.Errorf("%s.citations %q must be one of %s", field, group.Citations, strings.Join(citationModes, ", ")))
+		}
+		if group.Mode != "" && !slices.Contains(renderModes, group.Mode) {
+			errs = append(errs, fmt.Errorf("%s.mode %q must be one of %s", field, group.Mode, strings.Join(renderModes, ", ")))
+		}
+		if err := group.Globs.validate(field); err != nil {
+			errs = append(errs, err)
+		}
+		if dir := group.Globs.directory(); slices.Contains(codexReservedDirectories, strings.SplitN(dir, "/", 2)[0]) {
+			errs = append(errs, fmt.Errorf("%s.globs must not be rooted in %q, which is reserved for generated Codex files", field, dir))
+		}
+		if err := validateActivation(group, field); err != nil {
+			errs = append(errs, err)
+		}
+		if len(group.Sources) == 0 {
+			errs = append(errs, fmt.Errorf("%s.sources must list at least one file", field))
+		}
+		for j, src := range group.Sources {
+			if !strings.HasSuffix(src, ".md") {
+				errs = append(errs, fmt.Errorf("%s.sources[%d] %q must be a markdown file", field, j, src))
+			}
+			if strings.HasPrefix(src, "/") || strings.Contains(src, "..") {
+				errs = append(errs, fmt.Errorf("%s.sources[%d] %q must be relative to the repository root", field, j, src))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadManifest(t *testing.T) {
+	tests := []struct {
+		name        string
+		fileName    string
+		content     string
+		expectError string
+		expectCount int
+	}{
+		{
+			name:     "valid YAML manifest",
+			fileName: "rule-groups.yaml",
+			content: `version: 1
+groups:
+  - name: 'golang'
+    description: 'Go standards'
+    sources:
+      - 'Code-Style/GoLang.md'
+    globs: '**/*.go'
+  - name: 'git-flow'
+    description: 'Git workflow'
+    sources:
+      - 'Life-Cycle/Git-Flow.md'
+`,
+			expectCount: 2,
+		},
+		{
+			name:        "valid JSON manifest",
+			fileName:    "rule-groups.json",
+			content:     `{"version": 1, "groups": [{"name": "yaml", "description": "YAML standards", "sources": ["Code-Style/YAML.md"], "globs": "**/*.{yml,yaml}"}]}`,
+			expectCount: 1,
+		},
+		{
+			name:        "unknown field rejected",
+			fileName:    "rule-groups.yaml",
+			content:     "version: 1\ngroups:\n  - name: 'yaml'\n    description: 'YAML'\n    source: ['Code-Style/YAML.md']\n",
+			expectError: "field source not found",
+		},
+		{
+			name:        "unsupported version rejected",
+			fileName:    "rule-groups.yaml",
+			content:     "version: 2\ngroups:\n  - name: 'yaml'\n    description: 'YAML'\n    sources: ['Code-Style/YAML.md']\n",
+			expectError: "unsupported version 2",
+		},
+		{
+			name:        "duplicate group name rejected",
+			fileName:    "rule-groups.yaml",
+			content:     "version: 1\ngroups:\n  - name: 'yaml'\n    description: 'YAML'\n    sources: ['a.md']\n  - name: 'yaml'\n    description: 'YAML'\n    sources: ['b.md']\n",
+			expectError: "declared more than once",
+		},
+		{
+			name:        "invalid name and missing fields rejected",
+			fileName:    "rule-groups.yaml",
+			content:     "version: 1\ngroups:\n  - name: 'Go Lang'\n",
+			expectError: "must be lowercase kebab-case",
+		},
+		{
+			name:        "negative token budget rejected",
+			fileName:    "rule-groups.yaml",
+			content:     "version: 1\ngroups:\n  - name: 'yaml'\n    description: 'YAML'\n    sources: ['Code-Style/YAML.md']\n    token_budget: -1\n",
+			expectError: "token_budget must not be negative",
+		},
+		{
+			name:        "unknown citation mode rejected",
+			fileName:    "rule-groups.yaml",
+			content:     "version: 1\ngroups:\n  - name: 'yaml'\n    description: 'YAML'\n    sources: ['Code-Style/YAML.md']\n    citations: 'footnotes'\n",
+			expectError: `citations "footnotes" must be one of inline, sources`,
+		},
+		{
+			name:        "unknown render mode rejected",
+			fileName:    "rule-groups.yaml",
+			content:     "version: 1\ngroups:\n  - name: 'yaml'\n    description: 'YAML'\n    sources: ['Code-Style/YAML.md']\n    mode: 'short'\n",
+			expectError: `mode "short" must be one of full, compact, both`,
+		},
+		{
+			name:        "globs list with exclusions accepted",
+			fileName:    "rule-groups.yaml",
+			content:     "version: 1\ngroups:\n  - name: 'golang'\n    description: 'Go'\n    sources: ['Code-Style/GoLang.md']\n    globs:\n      - '**/*.go'\n      - '!**/*_mock.go'\n",
+			expectCount: 1,
+		},
+		{
+			name:        "globs made only of exclusions rejected",
+			fileName:    "rule-groups.yaml",
+			content:     "version: 1\ngroups:\n  - name: 'yaml'\n    description: 'YAML'\n    sources: ['Code-Style/YAML.md']\n    globs: ['!vendor/**']\n",
+			expectError: "groups[0].globs must include at least one pattern that is not negated",
+		},
+		{
+			name:        "invalid glob rejected",
+			fileName:    "rule-groups.yaml",
+			content:     "version: 1\ngroups:\n  - name: 'yaml'\n    description: 'YAML'\n    sources: ['Code-Style/YAML.md']\n    globs: '**/*.{yml,yaml'\n",
+			expectError: `groups[0].globs[0] "**/*.{yml,yaml": unclosed {`,
+		},
+		{
+			name:        "globs rooted in a reserved directory rejected",
+			fileName:    "rule-groups.yaml",
+			content:     "version: 1\ngroups:\n  - name: 'agents'\n    description: 'Agents'\n    sources: ['Agents.md']\n    globs: 'agents/**/*.md'\n",
+			expectError: `groups[0].globs must not be rooted in "agents", which is reserved for generated Codex files`,
+		},
+		{
+			name:        "agent-requested activation accepted",
+			fileName:    "rule-groups.yaml",
+			content:     "version: 1\ngroups:\n  - name: 'bulk-operations'\n    description: 'Bulk operations'\n    sources: ['Bulk.md']\n    activation: 'agent-requested'\n",
+			expectCount: 1,
+		},
+		{
+			name:        "unknown activation rejected",
+			fileName:    "rule-groups.yaml",
+			content:     "version: 1\ngroups:\n  - name: 'yaml'\n    description: 'YAML'\n    sources: ['Code-Style/YAML.md']\n    activation: 'auto'\n",
+			expectError: `groups[0].activation "auto" must be one of always, glob, agent-requested, manual`,
+		},
+		{
+			name:        "globs on a manual group rejected",
+			fileName:    "rule-groups.yaml",
+			content:     "version: 1\ngroups:\n  - name: 'yaml'\n    description: 'YAML'\n    sources: ['Code-Style/YAML.md']\n    globs: '**/*.yaml'\n    activation: 'manual'\n",
+			expectError: `groups[0].globs only apply to the "glob" activation, not "manual"`,
+		},
+		{
+			name:        "non-markdown source rejected",
+			fileName:    "rule-groups.yaml",
+			content:     "version: 1\ngroups:\n  - name: 'yaml'\n    description: 'YAML'\n    sources: ['../outside.txt']\n",
+			expectError: "must be a markdown file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			tmpDir := t.TempDir()
+			writeTestFile(t, tmpDir, tt.fileName, tt.content)
+
+			// when
+			groups, err := loadManifest(filepath.Join(tmpDir, tt.fileName))
+
+			// then
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("loadManifest() error = %v, want error containing %q", err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadManifest() error: %v", err)
+			}
+			if len(groups) != tt.expectCount {
+				t.Errorf("loadManifest() returned %d groups, want %d", len(groups), tt.expectCount)
+			}
+		})
+	}
+}
+
+func TestLoadManifestMissingFile(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "missing.yaml")
+
+	// when
+	_, err := loadManifest(path)
+
+	// then
+	if err == nil {
+		t.Fatal("loadManifest() should fail for a missing file")
+	}
+}
+
+func TestRepositoryManifestIsValid(t *testing.T) {
+	// given
+	path := "rule-groups.yaml"
+
+	// when
+	groups, err := loadManifest(path)
+
+	// then
+	if err != nil {
+		t.Fatalf("committed manifest is invalid: %v", err)
+	}
+	if len(groups) == 0 {
+		t.Error("committed manifest should declare rule groups")
+	}
+}
+
+func TestRepositoryGroupsAreValid(t *testing.T) {
+	// given
+	repoRoot := filepath.Join("..", "..", "..")
+	groups, err := ruleGroups()
+	if err != nil {
+		t.Fatalf("ruleGroups() error: %v", err)
+	}
+	discovered, _, err := discoverLanguageGroups(repoRoot)
+	if err != nil {
+		t.Fatalf("discoverLanguageGroups() error: %v", err)
+	}
+	metas, err := scanPageMeta(repoRoot)
+	if err != nil {
+		t.Fatalf("scanPageMeta() error: %v", err)
+	}
+
+	// when
+	groups, err = applyPageMeta(mergeGroups(discovered, groups), metas)
+	if err == nil {
+		err = validateGroups(groups)
+	}
+
+	// then
+	if err != nil {
+		t.Errorf("the embedded, discovered, and front matter groups of this repository are invalid: %v", err)
+	}
+}
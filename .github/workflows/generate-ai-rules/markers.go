@@ -0,0 +1,191 @@
+package main
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	logger "github.com/sirupsen/logrus"
+	"github.com/yuin/goldmark/ast"
+)
+
+// markerRegex matches HTML comment markers such as <!-- ai:exclude --> or <!-- /ai:exclude -->.
+// The name is the part before the colon and the value the part after it.
+var markerRegex = regexp.MustCompile(`^<!--\s*(/?)([a-z]+)(?::\s*([a-z0-9,\- ]+?))?\s*-->$`)
+
+// marker is an HTML comment marker found in a source page, either on a line of
+// its own (an HTML block) or inside a paragraph (raw inline HTML).
+type marker struct {
+	Closing bool
+	Name    string
+	Value   string
+	Start   int // offset of the marker, or of its line for block markers
+	Stop    int // offset past the marker, or past its line break for block markers
+}
+
+// findMarkers returns the comment markers below node in document order.
+// Comments inside code blocks and code spans are not HTML nodes, so they are never markers.
+func findMarkers(node ast.Node, source []byte) []marker {
+	var markers []marker
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		start, stop, raw, ok := htmlSource(n, source)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+		match := markerRegex.FindStringSubmatch(strings.TrimSpace(raw))
+		if match == nil {
+			return ast.WalkContinue, nil
+		}
+		markers = append(markers, marker{
+			Closing: match[1] == "/",
+			Name:    match[2],
+			Value:   strings.TrimSpace(match[3]),
+			Start:   start,
+			Stop:    stop,
+		})
+		return ast.WalkContinue, nil
+	})
+	return markers
+}
+
+// markerRegion is the source between an opening marker and its closing marker.
+type markerRegion struct {
+	Open  marker
+	Close marker
+}
+
+// pairMarkers matches the opening and closing markers with the given name. A closing
+// marker without a value (e.g. <!-- /target -->) closes the most recent open marker;
+// one with a value (e.g. <!-- /ai:exclude -->) closes the most recent one with that value.
+// Markers left unpaired are returned separately so they can be reported and removed.
+func pairMarkers(markers []marker, name string) ([]markerRegion, []marker) {
+	var regions []markerRegion
+	var open, stray []marker
+	for _, m := range markers {
+		if m.Name != name {
+			continue
+		}
+		if !m.Closing {
+			open = append(open, m)
+			continue
+		}
+		matched := -1
+		for i := len(open) - 1; i >= 0; i-- {
+			if m.Value == "" || open[i].Value == m.Value {
+				matched = i
+				break
+			}
+		}
+		if matched < 0 {
+			stray = append(stray, m)
+			continue
+		}
+		regions = append(regions, markerRegion{Open: open[matched], Close: m})
+		open = append(open[:matched], open[matched+1:]...)
+	}
+	return regions, append(stray, open...)
+}
+
+// applyAIMarkers honours the <!-- ai:exclude --> and <!-- ai:only --> markers:
+// excluded regions are dropped together with their markers, while ai:only regions
+// keep their content (it is meant for assistants, not for the wiki) and lose the markers.
+func applyAIMarkers(node ast.Node, source []byte) []edit {
+	if node.Kind() != ast.KindDocument {
+		return nil
+	}
+	regions, stray := pairMarkers(findMarkers(node, source), "ai")
+
+	var edits []edit
+	for _, region := range regions {
+		switch region.Open.Value {
+		case "exclude":
+			edits = append(edits, edit{Start: region.Open.Start, Stop: region.Close.Stop})
+		case "only":
+			edits = append(edits,
+				edit{Start: region.Open.Start, Stop: region.Open.Stop},
+				edit{Start: region.Close.Start, Stop: region.Close.Stop},
+			)
+		default:
+			stray = append(stray, region.Open, region.Close)
+		}
+	}
+	for _, m := range stray {
+		logger.WithFields(logger.Fields{
+			"marker": strings.TrimSpace(string(source[m.Start:m.Stop])),
+			"offset": m.Start,
+		}).Warn("ignored unpaired or unknown AI marker")
+		edits = append(edits, edit{Start: m.Start, Stop: m.Stop})
+	}
+	return edits
+}
+
+// selectTargetBlocks returns a visitor for <!-- target:claude,cursor -->...<!-- /target -->
+// blocks: blocks naming target keep their content and lose the markers, while blocks for
+// other targets are dropped. Unpaired markers are removed (checkTargetBlocks reports them).
+func selectTargetBlocks(target string) visitor {
+	return func(node ast.Node, source []byte) []edit {
+		if node.Kind() != ast.KindDocument {
+			return nil
+		}
+		regions, stray := pairMarkers(findMarkers(node, source), "target")
+
+		var edits []edit
+		for _, region := range regions {
+			if !slices.Contains(markerTargets(region.Open), target) {
+				edits = append(edits, edit{Start: region.Open.Start, Stop: region.Close.Stop})
+				continue
+			}
+			edits = append(edits,
+				edit{Start: region.Open.Start, Stop: region.Open.Stop},
+				edit{Start: region.Close.Start, Stop: region.Close.Stop},
+			)
+		}
+		for _, m := range stray {
+			edits = append(edits, edit{Start: m.Start, Stop: m.Stop})
+		}
+		return edits
+	}
+}
+
+// checkTargetBlocks reports target blocks that are unpaired or name unknown targets.
+// It makes no edits, so the warnings are logged once per page rather than once per target.
+func checkTargetBlocks(node ast.Node, source []byte) []edit {
+	if node.Kind() != ast.KindDocument {
+		return nil
+	}
+	regions, stray := pairMarkers(findMarkers(node, source), "target")
+	for _, m := range stray {
+		logger.WithFields(logger.Fields{
+			"marker": strings.TrimSpace(string(source[m.Start:m.Stop])),
+			"offset": m.Start,
+		}).Warn("ignored unpaired target marker")
+	}
+
+	known := targetNames(targets())
+	for _, region := range regions {
+		for _, name := range markerTargets(region.Open) {
+			if !slices.Contains(known, name) {
+				logger.WithFields(logger.Fields{
+					"target":    name,
+					"offset":    region.Open.Start,
+					"available": known,
+				}).Warn("target block names an unknown target")
+			}
+		}
+	}
+	return nil
+}
+
+// markerTargets returns the comma-separated target names of a target marker.
+func markerTargets(m marker) []string {
+	var names []string
+	for _, name := range strings.Split(m.Value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
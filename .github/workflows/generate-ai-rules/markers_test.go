@@ -0,0 +1,147 @@
+package main
+
+import "testing"
+
+func TestApplyAIMarkers(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "excluded block removed with its markers",
+			input:    "Rule.\n\n<!-- ai:exclude -->\nSome history.\n\n![](.assets/timeline.png)\n<!-- /ai:exclude -->\n\nNext rule.\n",
+			expected: "Rule.\n\nNext rule.\n",
+		},
+		{
+			name:     "ai-only block kept without its markers",
+			input:    "Rule.\n\n<!-- ai:only -->\nPrefer the smallest diff.\n<!-- /ai:only -->\n",
+			expected: "Rule.\n\nPrefer the smallest diff.\n",
+		},
+		{
+			name:     "inline markers",
+			input:    "Use tabs<!-- ai:exclude --> (we tried spaces in 2019)<!-- /ai:exclude -->.\n",
+			expected: "Use tabs.\n",
+		},
+		{
+			name:     "markers inside fenced code untouched",
+			input:    "```markdown\n<!-- ai:exclude -->\nHidden.\n<!-- /ai:exclude -->\n```\n",
+			expected: "```markdown\n<!-- ai:exclude -->\nHidden.\n<!-- /ai:exclude -->\n```\n",
+		},
+		{
+			name:     "unclosed marker removed and content kept",
+			input:    "<!-- ai:exclude -->\nStill here.\n",
+			expected: "Still here.\n",
+		},
+		{
+			name:     "other comments removed by the sanitizer",
+			input:    "<!-- TODO: expand -->\nText.\n",
+			expected: "Text.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			input := tt.input
+
+			// when
+			result := transformContent(input, "claude")
+
+			// then
+			if result != tt.expected {
+				t.Errorf("transformContent()\n  got:  %q\n  want: %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPairMarkers(t *testing.T) {
+	// given
+	markers := []marker{
+		{Name: "target", Value: "claude", Start: 0},
+		{Name: "ai", Value: "only", Start: 1},
+		{Name: "target", Value: "cursor", Start: 2},
+		{Name: "target", Closing: true, Start: 3},
+		{Name: "target", Closing: true, Start: 4},
+		{Name: "target", Closing: true, Start: 5},
+	}
+
+	// when
+	regions, stray := pairMarkers(markers, "target")
+
+	// then
+	if len(regions) != 2 {
+		t.Fatalf("pairMarkers() returned %d regions, want 2", len(regions))
+	}
+	if regions[0].Open.Start != 2 || regions[0].Close.Start != 3 {
+		t.Errorf("regions[0] = %+v, want the inner cursor block", regions[0])
+	}
+	if regions[1].Open.Start != 0 || regions[1].Close.Start != 4 {
+		t.Errorf("regions[1] = %+v, want the outer claude block", regions[1])
+	}
+	if len(stray) != 1 || stray[0].Start != 5 {
+		t.Errorf("stray = %+v, want the extra closing marker", stray)
+	}
+}
+
+func TestSelectTargetBlocks(t *testing.T) {
+	input := "Shared rule.\n\n<!-- target:claude,cursor -->\nUse the changelog-guard hook.\n<!-- /target -->\n\n<!-- target:codex -->\nSee the prefix_rule notes.\n<!-- /target -->\n\nLast rule.\n"
+	tests := []struct {
+		name     string
+		target   string
+		expected string
+	}{
+		{
+			name:     "listed target keeps its block",
+			target:   "cursor",
+			expected: "Shared rule.\n\nUse the changelog-guard hook.\n\nLast rule.\n",
+		},
+		{
+			name:     "other blocks removed",
+			target:   "codex",
+			expected: "Shared rule.\n\nSee the prefix_rule notes.\n\nLast rule.\n",
+		},
+		{
+			name:     "unlisted target sees shared content only",
+			target:   "aider",
+			expected: "Shared rule.\n\nLast rule.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			target := tt.target
+
+			// when
+			result := transformContent(input, target)
+
+			// then
+			if result != tt.expected {
+				t.Errorf("transformContent(%q)\n  got:  %q\n  want: %q", target, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPageRendersEveryTargetFromOneParse(t *testing.T) {
+	// given
+	parsed := parsePage("# Rules\n\n<!-- target:claude -->\nClaude only.\n<!-- /target -->\n\nShared.\n")
+
+	// when
+	claude := parsed.render(selectTargetBlocks("claude"))
+	cursor := parsed.render(selectTargetBlocks("cursor"))
+	again := parsed.render(selectTargetBlocks("claude"))
+
+	// then
+	if claude != "# Rules\n\nClaude only.\n\nShared.\n" {
+		t.Errorf("render(claude) = %q", claude)
+	}
+	if cursor != "# Rules\n\nShared.\n" {
+		t.Errorf("render(cursor) = %q", cursor)
+	}
+	if again != claude {
+		t.Errorf("second render(claude) = %q, want %q", again, claude)
+	}
+}
@@ -1,145 +1,530 @@
 package main
 
 import (
+	"bytes"
 	"regexp"
+	"sort"
 	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
 )
 
-// Compiled regex patterns for content transformation.
-var (
-	// imageRegex matches markdown image syntax: ![alt](path)
-	imageRegex = regexp.MustCompile(`!\[([^\]]*)\]\(([^)]+)\)`)
+// markdown parses documents as CommonMark with the GitHub Flavored Markdown extensions
+// and footnotes. The footnote parsers are registered without their AST transformer,
+// which would drop definitions that are never referenced.
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithParserOptions(
+		parser.WithBlockParsers(util.Prioritized(extension.NewFootnoteBlockParser(), 999)),
+		parser.WithInlineParsers(util.Prioritized(extension.NewFootnoteParser(), 101)),
+	),
+)
 
-	// referenceSectionRegex matches the ## References heading.
-	referenceSectionRegex = regexp.MustCompile(`(?m)^## References\s*$`)
+// edit replaces source[Start:Stop] with Text. Transforms never re-render the AST;
+// they describe byte edits on the original source, so everything they do not touch
+// (fenced code, inline code, tables, spacing) is copied through verbatim.
+type edit struct {
+	Start int
+	Stop  int
+	Text  string
+}
 
-	// footnoteRefRegex matches inline footnote references like [^1].
-	footnoteRefRegex = regexp.MustCompile(`\[\^\d+\]`)
+// visitor inspects a single AST node and returns the edits it makes to the source.
+type visitor func(node ast.Node, source []byte) []edit
 
-	// footnoteDefRegex matches footnote definition lines like [^1]: text.
-	footnoteDefRegex = regexp.MustCompile(`(?m)^\[\^\d+\]:.*$`)
+// contentVisitors are the transforms applied to every source page, whatever the target.
+// Footnotes are left to footnoteVisitor, since they depend on the group's citation mode.
+var contentVisitors = []visitor{
+	applyAIMarkers,
+	checkTargetBlocks,
+	stripImages,
+	stripReferences,
+	stripSubPageLinks,
+}
 
-	// mdLinkRegex matches [text](path.md) markdown links (lazy quantifier for parentheses in paths).
-	mdLinkRegex = regexp.MustCompile(`\[([^\]]+)\]\((.+?\.md)\)`)
+// page is a parsed source page. The edits shared by every target are collected once,
+// so rendering the page for another target only walks the AST with the visitors that
+// depend on the target, such as target blocks and link resolution.
+type page struct {
+	source []byte
+	doc    ast.Node
+	edits  []edit
+}
 
-	// subPageLinkLineRegex matches bullet list items that are .md links.
-	subPageLinkLineRegex = regexp.MustCompile(`(?m)^-\s+\[([^\]]+)\]\(([^)]+\.md)\).*$`)
+// parsePage parses a source page and collects the edits of contentVisitors, followed by
+// those of the given visitors, which depend on the configuration rather than on the target.
+func parsePage(content string, visitors ...visitor) page {
+	source := []byte(content)
+	doc := markdown.Parser().Parse(text.NewReader(source))
+	shared := append(append([]visitor{}, contentVisitors...), visitors...)
+	return page{source: source, doc: doc, edits: collectEdits(doc, source, shared)}
+}
 
-	// multipleBlankLines matches 3+ consecutive newlines (2+ blank lines).
-	multipleBlankLines = regexp.MustCompile(`\n{3,}`)
-)
+// render returns the transformed page, adding the edits of the target-specific visitors.
+func (p page) render(visitors ...visitor) string {
+	edits := append(collectEdits(p.doc, p.source, visitors), p.edits...)
+	return collapseWhitespace(applyEdits(p.source, edits))
+}
 
-// transformContent applies all content transformations to a markdown string.
-func transformContent(content string) string {
-	content = stripImages(content)
-	content = stripReferences(content)
-	content = stripFootnotes(content)
-	content = stripSubPageLinks(content)
-	content = transformLinks(content)
-	content = collapseWhitespace(content)
-	return content
-}
-
-// stripImages removes internal markdown images and cleans up empty table rows.
-// External images (http/https URLs) are preserved.
-func stripImages(content string) string {
-	lines := strings.Split(content, "\n")
-	var result []string
-	for _, line := range lines {
-		cleaned := imageRegex.ReplaceAllStringFunc(line, func(match string) string {
-			groups := imageRegex.FindStringSubmatch(match)
-			if len(groups) >= 3 && strings.HasPrefix(groups[2], "http") {
-				return match
-			}
-			return ""
-		})
-		cleaned = strings.TrimRight(cleaned, " ")
-		// skip lines that became empty after image removal
-		if cleaned == "" {
-			result = append(result, cleaned)
-			continue
+// transformContent applies all content transformations to a markdown string for target,
+// reducing internal links to their display text, dropping footnotes, and sanitizing HTML
+// with the default allowlist.
+func transformContent(content string, target string) string {
+	sanitizer := newHTMLSanitizer(defaultAllowedHTML)
+	return parsePage(content, sanitizer.visit).render(selectTargetBlocks(target), transformLinks, stripFootnotes)
+}
+
+// rewrite parses content once, walks the AST with every visitor, and applies the collected edits.
+func rewrite(content string, visitors ...visitor) string {
+	source := []byte(content)
+	doc := markdown.Parser().Parse(text.NewReader(source))
+	return applyEdits(source, collectEdits(doc, source, visitors))
+}
+
+// collectEdits walks doc once and returns the edits of every visitor.
+func collectEdits(doc ast.Node, source []byte, visitors []visitor) []edit {
+	var edits []edit
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		for _, visit := range visitors {
+			edits = append(edits, visit(node, source)...)
 		}
-		// skip table rows that only contain pipes and whitespace after image removal
-		if isEmptyTableRow(cleaned) {
+		return ast.WalkContinue, nil
+	})
+	return edits
+}
+
+// applyEdits applies non-overlapping edits to source. When edits overlap, the one that
+// starts first (or, at the same offset, the longer one) wins, so removing a whole block
+// also discards any finer-grained edits inside it. An insertion (an empty range) overlaps
+// nothing, so it is applied before any edit starting at the same offset.
+func applyEdits(source []byte, edits []edit) string {
+	sort.SliceStable(edits, func(a, b int) bool {
+		if edits[a].Start != edits[b].Start {
+			return edits[a].Start < edits[b].Start
+		}
+		if inserts := edits[a].Start == edits[a].Stop; inserts != (edits[b].Start == edits[b].Stop) {
+			return inserts
+		}
+		return edits[a].Stop > edits[b].Stop
+	})
+
+	var sb strings.Builder
+	last := 0
+	for _, e := range edits {
+		if e.Start < last {
 			continue
 		}
-		result = append(result, cleaned)
+		sb.Write(source[last:e.Start])
+		sb.WriteString(e.Text)
+		last = e.Stop
 	}
-	return strings.Join(result, "\n")
+	sb.Write(source[last:])
+	return sb.String()
 }
 
-// isEmptyTableRow checks if a line is a table row with no meaningful content.
-func isEmptyTableRow(line string) bool {
-	trimmed := strings.TrimSpace(line)
-	if !strings.HasPrefix(trimmed, "|") {
+// stripImages removes internal images and table rows left empty by the removal.
+// External images (http/https URLs) are preserved. Diagram sidecars (see diagrams.go)
+// take the place of the images that have one.
+func stripImages(node ast.Node, source []byte) []edit {
+	switch n := node.(type) {
+	case *ast.Image:
+		if isExternal(n.Destination) {
+			return nil
+		}
+		return []edit{deleteInline(source, n.Pos(), linkEnd(source, labelEnd(source, n.Pos())))}
+	case *east.TableRow:
+		for cell := n.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			if !onlyInternalImages(cell, source) {
+				return nil
+			}
+		}
+		// drop the whole line, including the line break before it
+		return []edit{{Start: max(n.Pos()-1, 0), Stop: lineEnd(source, n.Pos())}}
+	}
+	return nil
+}
+
+// onlyInternalImages reports whether a table cell holds nothing but internal images.
+func onlyInternalImages(cell ast.Node, source []byte) bool {
+	for child := cell.FirstChild(); child != nil; child = child.NextSibling() {
+		switch c := child.(type) {
+		case *ast.Image:
+			if isExternal(c.Destination) {
+				return false
+			}
+		case *ast.Text:
+			if len(bytes.TrimSpace(c.Segment.Value(source))) > 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// stripReferences removes the top-level "## References" section and everything after it.
+func stripReferences(node ast.Node, source []byte) []edit {
+	if !isReferencesHeading(node, source) {
+		return nil
+	}
+	start := blockStart(source, node)
+	for start > 0 && source[start-1] == '\n' {
+		start--
+	}
+	return []edit{{Start: start, Stop: len(source), Text: "\n"}}
+}
+
+// isReferencesHeading reports whether node is the top-level "## References" heading.
+func isReferencesHeading(node ast.Node, source []byte) bool {
+	heading, ok := node.(*ast.Heading)
+	if !ok || heading.Level != 2 || heading.Parent().Kind() != ast.KindDocument {
 		return false
 	}
-	remaining := strings.ReplaceAll(trimmed, "|", "")
-	remaining = strings.TrimSpace(remaining)
-	return remaining == ""
+	return strings.TrimSpace(inlineText(heading, source)) == "References"
+}
+
+// danglingFootnoteRegex matches the rest of a footnote reference [^N] after its "[".
+var danglingFootnoteRegex = regexp.MustCompile(`\A\^[^\]\s]+\]`)
+
+// stripFootnotes removes footnote references [^N] and footnote definitions, along with the
+// line breaks before each definition, so no run of empty lines is left where they stood.
+// References without a definition are plain text to the parser, which ends a text node at
+// their "[", so they are matched from there.
+func stripFootnotes(node ast.Node, source []byte) []edit {
+	switch n := node.(type) {
+	case *east.FootnoteLink:
+		return []edit{{Start: n.Pos(), Stop: labelEnd(source, n.Pos()) + 1}}
+	case *east.Footnote:
+		start := n.Pos()
+		for start > 0 && source[start-1] == '\n' {
+			start--
+		}
+		return []edit{{Start: start, Stop: blockEnd(source, n)}}
+	case *ast.Text:
+		stop := n.Segment.Stop
+		if _, inCode := n.Parent().(*ast.CodeSpan); inCode || stop == 0 || source[stop-1] != '[' {
+			return nil
+		}
+		if match := danglingFootnoteRegex.Find(source[stop:]); match != nil {
+			return []edit{{Start: stop - 1, Stop: stop + len(match)}}
+		}
+	}
+	return nil
+}
+
+// stripSubPageLinks removes top-level bullet items that start with a link to an internal page,
+// which is how the wiki lists sub-pages.
+func stripSubPageLinks(node ast.Node, source []byte) []edit {
+	item, ok := node.(*ast.ListItem)
+	if !ok {
+		return nil
+	}
+	list := item.Parent().(*ast.List)
+	if list.IsOrdered() || list.Parent().Kind() != ast.KindDocument || item.FirstChild() == nil {
+		return nil
+	}
+	link, ok := item.FirstChild().FirstChild().(*ast.Link)
+	if !ok || !isInternalPage(link.Destination) {
+		return nil
+	}
+	return []edit{{Start: item.Pos(), Stop: blockEnd(source, item)}}
 }
 
-// stripReferences removes the ## References section and everything after it.
-func stripReferences(content string) string {
-	loc := referenceSectionRegex.FindStringIndex(content)
-	if loc == nil {
-		return content
+// transformLinks replaces links to internal pages with their display text.
+// Only the brackets and destination are removed, so edits inside the label still apply.
+func transformLinks(node ast.Node, source []byte) []edit {
+	link, ok := node.(*ast.Link)
+	if !ok || !isInternalPage(link.Destination) {
+		return nil
+	}
+	closing := labelEnd(source, link.Pos())
+	return []edit{
+		{Start: link.Pos(), Stop: link.Pos() + 1},
+		{Start: closing, Stop: linkEnd(source, closing)},
 	}
-	return strings.TrimRight(content[:loc[0]], "\n") + "\n"
 }
 
-// stripFootnotes removes footnote references [^N] and definition lines [^N]: text.
-func stripFootnotes(content string) string {
-	content = footnoteDefRegex.ReplaceAllString(content, "")
-	content = footnoteRefRegex.ReplaceAllString(content, "")
-	return content
+// isExternal reports whether a link destination points outside the repository.
+func isExternal(destination []byte) bool {
+	return bytes.HasPrefix(destination, []byte("http"))
 }
 
-// stripSubPageLinks removes bullet-list lines that link to internal .md files.
-func stripSubPageLinks(content string) string {
-	return subPageLinkLineRegex.ReplaceAllStringFunc(content, func(match string) string {
-		groups := subPageLinkLineRegex.FindStringSubmatch(match)
-		if len(groups) >= 3 && strings.HasPrefix(groups[2], "http") {
-			return match
+// isInternalPage reports whether a link destination points to a markdown page in the repository.
+func isInternalPage(destination []byte) bool {
+	if isExternal(destination) {
+		return false
+	}
+	page, _, _ := bytes.Cut(destination, []byte("#"))
+	return bytes.HasSuffix(page, []byte(".md"))
+}
+
+// inlineText returns the concatenated text segments below node.
+func inlineText(node ast.Node, source []byte) string {
+	var sb strings.Builder
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if t, ok := n.(*ast.Text); ok && entering {
+			sb.Write(t.Segment.Value(source))
 		}
-		return ""
+		return ast.WalkContinue, nil
 	})
+	return sb.String()
+}
+
+// labelEnd returns the offset of the bracket closing the link label that starts at pos
+// (at the "[" or at the "!" of an image). goldmark records where inline nodes start but
+// not where they end, so the label is matched here, skipping escapes and code spans.
+func labelEnd(source []byte, pos int) int {
+	i := pos
+	if source[i] == '!' {
+		i++
+	}
+	depth := 0
+	for ; i < len(source); i++ {
+		switch source[i] {
+		case '\\':
+			i++
+		case '`':
+			i = codeSpanEnd(source, i)
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(source) - 1
 }
 
-// transformLinks converts [text](path.md) to just the display text for internal links.
-func transformLinks(content string) string {
-	return mdLinkRegex.ReplaceAllStringFunc(content, func(match string) string {
-		groups := mdLinkRegex.FindStringSubmatch(match)
-		if len(groups) < 3 {
-			return match
+// linkEnd returns the offset just past the destination that follows the label closing at labelEnd:
+// an inline "(destination "title")", a full reference "[ref]", or nothing for shortcut references.
+func linkEnd(source []byte, labelEnd int) int {
+	i := labelEnd + 1
+	if i >= len(source) {
+		return len(source)
+	}
+	switch source[i] {
+	case '(':
+		depth := 0
+		var quote byte
+		for ; i < len(source); i++ {
+			c := source[i]
+			switch {
+			case c == '\\':
+				i++
+			case quote != 0:
+				if c == quote {
+					quote = 0
+				}
+			case (c == '"' || c == '\'') && (source[i-1] == ' ' || source[i-1] == '\t'):
+				quote = c
+			case c == '<':
+				for i < len(source) && source[i] != '>' {
+					i++
+				}
+			case c == '(':
+				depth++
+			case c == ')':
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			}
+		}
+		return len(source)
+	case '[':
+		if closing := bytes.IndexByte(source[i:], ']'); closing >= 0 {
+			return i + closing + 1
+		}
+	}
+	return i
+}
+
+// codeSpanEnd returns the offset of the last backtick of the code span opening at pos,
+// or the end of the opening run when the span is never closed.
+func codeSpanEnd(source []byte, pos int) int {
+	run := 0
+	for pos+run < len(source) && source[pos+run] == '`' {
+		run++
+	}
+	for i := pos + run; i < len(source); {
+		if source[i] != '`' {
+			i++
+			continue
+		}
+		n := 0
+		for i+n < len(source) && source[i+n] == '`' {
+			n++
 		}
-		linkPath := groups[2]
-		// keep external links unchanged
-		if strings.HasPrefix(linkPath, "http") {
-			return match
+		if n == run {
+			return i + n - 1
 		}
-		return groups[1]
+		i += n
+	}
+	return pos + run - 1
+}
+
+// deleteInline removes source[start:stop], together with the spaces before it
+// when nothing but the line break follows, so no trailing whitespace is left behind.
+func deleteInline(source []byte, start, stop int) edit {
+	if stop == lineEnd(source, stop) {
+		for start > 0 && (source[start-1] == ' ' || source[start-1] == '\t') {
+			start--
+		}
+	}
+	return edit{Start: start, Stop: stop}
+}
+
+// blockStart returns the offset of the first line of a block node.
+func blockStart(source []byte, node ast.Node) int {
+	if node.Lines().Len() == 0 {
+		return node.Pos()
+	}
+	return bytes.LastIndexByte(source[:node.Lines().At(0).Start], '\n') + 1
+}
+
+// blockEnd returns the end of the last source line covered by node or its descendants,
+// excluding the line break.
+func blockEnd(source []byte, node ast.Node) int {
+	end := node.Pos()
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering && n.Type() == ast.TypeBlock {
+			if lines := n.Lines(); lines.Len() > 0 {
+				end = max(end, lines.At(lines.Len()-1).Stop)
+			}
+		}
+		return ast.WalkContinue, nil
 	})
+	return lineEnd(source, end)
 }
 
-// collapseWhitespace reduces multiple blank lines and trims trailing whitespace.
+// lineEnd returns the offset of the line break ending the line that contains pos,
+// or the end of the source.
+func lineEnd(source []byte, pos int) int {
+	if i := bytes.IndexByte(source[pos:], '\n'); i >= 0 {
+		return pos + i
+	}
+	return len(source)
+}
+
+// collapseWhitespace reduces multiple blank lines and trims leading blank lines and trailing whitespace.
+// Blank lines inside code blocks are kept as written.
 func collapseWhitespace(content string) string {
-	content = multipleBlankLines.ReplaceAllString(content, "\n\n")
-	content = strings.TrimRight(content, "\n ") + "\n"
-	return content
+	source := []byte(content)
+	doc := markdown.Parser().Parse(text.NewReader(source))
+	var code []text.Segment
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if entering && (node.Kind() == ast.KindFencedCodeBlock || node.Kind() == ast.KindCodeBlock) {
+			if lines := node.Lines(); lines.Len() > 0 {
+				code = append(code, text.NewSegment(lines.At(0).Start, lines.At(lines.Len()-1).Stop))
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	inCode := func(pos int) bool {
+		for _, segment := range code {
+			if pos >= segment.Start && pos < segment.Stop {
+				return true
+			}
+		}
+		return false
+	}
+
+	var sb strings.Builder
+	blank := 1 // drop leading blank lines, left behind when a transform removes the top of a page
+	pos := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if strings.TrimSpace(line) == "" && !inCode(pos) {
+			blank++
+		} else {
+			blank = 0
+		}
+		if blank <= 1 {
+			sb.WriteString(line)
+		}
+		pos += len(line)
+	}
+	return strings.TrimRight(sb.String(), "\n ") + "\n"
 }
 
-// mergeContents concatenates multiple transformed contents with a separator.
-func mergeContents(contents []string) string {
-	var nonEmpty []string
+// mergeContents joins the transformed pages of a group under a single H1 taken from the
+// group description. Every page heading is demoted one level, so each page's H1 becomes an
+// H2 in the merged outline. When the group asks for it, a generated outline follows the H1.
+func mergeContents(group RuleGroup, contents []string) string {
+	var pages []string
 	for _, c := range contents {
 		trimmed := strings.TrimSpace(c)
 		if trimmed != "" {
-			nonEmpty = append(nonEmpty, trimmed)
+			pages = append(pages, strings.TrimSpace(rewrite(trimmed, demoteHeadings)))
 		}
 	}
-	if len(nonEmpty) == 0 {
+	if len(pages) == 0 {
+		return ""
+	}
+
+	body := strings.Join(pages, "\n\n---\n\n") + "\n"
+	merged := "# " + group.Description + "\n\n"
+	if group.Outline {
+		merged += formatOutline(body)
+	}
+	return merged + body
+}
+
+// demoteHeadings moves every heading one level down, stopping at H6.
+// Setext headings are rewritten as ATX headings, since only levels 1 and 2 have a setext form.
+func demoteHeadings(node ast.Node, source []byte) []edit {
+	heading, ok := node.(*ast.Heading)
+	if !ok || heading.Level >= 6 {
+		return nil
+	}
+	lines := heading.Lines()
+	if source[heading.Pos()] == '#' && (lines.Len() == 0 || heading.Pos() < lines.At(0).Start) {
+		return []edit{{Start: heading.Pos(), Stop: heading.Pos(), Text: "#"}}
+	}
+
+	var title []string
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		title = append(title, strings.TrimSpace(string(line.Value(source))))
+	}
+	underline := lineEnd(source, lines.At(lines.Len()-1).Start)
+	if underline < len(source) {
+		underline = lineEnd(source, underline+1)
+	}
+	return []edit{{
+		Start: blockStart(source, heading),
+		Stop:  underline,
+		Text:  strings.Repeat("#", heading.Level+1) + " " + strings.Join(title, " "),
+	}}
+}
+
+// formatOutline lists the H2 and H3 headings of content as a nested bullet list.
+func formatOutline(content string) string {
+	source := []byte(content)
+	doc := markdown.Parser().Parse(text.NewReader(source))
+
+	var sb strings.Builder
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := node.(*ast.Heading)
+		if !entering || !ok || heading.Level < 2 || heading.Level > 3 {
+			return ast.WalkContinue, nil
+		}
+		sb.WriteString(strings.Repeat("  ", heading.Level-2) + "- " + inlineText(heading, source) + "\n")
+		return ast.WalkSkipChildren, nil
+	})
+	if sb.Len() == 0 {
 		return ""
 	}
-	return strings.Join(nonEmpty, "\n\n---\n\n") + "\n"
+	return "## Contents\n\n" + sb.String() + "\n"
 }
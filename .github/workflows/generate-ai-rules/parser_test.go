@@ -46,7 +46,7 @@ func TestStripImages(t *testing.T) {
 			input := tt.input
 
 			// when
-			result := stripImages(input)
+			result := rewrite(input, stripImages)
 
 			// then
 			if result != tt.expected {
@@ -85,7 +85,7 @@ func TestStripReferences(t *testing.T) {
 			input := tt.input
 
 			// when
-			result := stripReferences(input)
+			result := rewrite(input, stripReferences)
 
 			// then
 			if result != tt.expected {
@@ -109,7 +109,22 @@ func TestStripFootnotes(t *testing.T) {
 		{
 			name:     "footnote definition lines removed",
 			input:    "Text here.\n\n[^1]: Some reference link\n[^2]: Another reference\n",
-			expected: "Text here.\n\n\n\n",
+			expected: "Text here.\n",
+		},
+		{
+			name:     "defined footnote references and definitions removed",
+			input:    "Clean Architecture[^1] is important[^2].\n\n[^1]: Martin\n[^2]: Fowler\n",
+			expected: "Clean Architecture is important.\n",
+		},
+		{
+			name:     "definitions between paragraphs leave a single blank line",
+			input:    "First[^note].\n\n[^note]: A note\n\nSecond.\n",
+			expected: "First.\n\nSecond.\n",
+		},
+		{
+			name:     "footnote syntax in code unchanged",
+			input:    "Write `[^1]` after the sentence.\n",
+			expected: "Write `[^1]` after the sentence.\n",
 		},
 		{
 			name:     "no footnotes unchanged",
@@ -124,7 +139,7 @@ func TestStripFootnotes(t *testing.T) {
 			input := tt.input
 
 			// when
-			result := stripFootnotes(input)
+			result := rewrite(input, stripFootnotes)
 
 			// then
 			if result != tt.expected {
@@ -165,6 +180,11 @@ func TestTransformLinks(t *testing.T) {
 			input:    "See [A](a.md) and [B](b.md) here.",
 			expected: "See A and B here.",
 		},
+		{
+			name:     "internal link with anchor converted",
+			input:    "Use the [operations vocabulary](../../Code-Style.md#operations-vocabulary).",
+			expected: "Use the operations vocabulary.",
+		},
 		{
 			name:     "link with parentheses in filename",
 			input:    "See [PEP 8](Code-Style/Python/Styling-and-Formatting-(PEP-8).md).",
@@ -178,7 +198,7 @@ func TestTransformLinks(t *testing.T) {
 			input := tt.input
 
 			// when
-			result := transformLinks(input)
+			result := rewrite(input, transformLinks)
 
 			// then
 			if result != tt.expected {
@@ -222,7 +242,7 @@ func TestStripSubPageLinks(t *testing.T) {
 			input := tt.input
 
 			// when
-			result := stripSubPageLinks(input)
+			result := rewrite(input, stripSubPageLinks)
 
 			// then
 			if result != tt.expected {
@@ -232,6 +252,137 @@ func TestStripSubPageLinks(t *testing.T) {
 	}
 }
 
+func TestTransformContentLeavesCodeUntouched(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "fenced shell block",
+			input: "```bash\nfor repo in $(gh repo list); do\n  echo \"[${repo}](${repo}.md) ![x](.assets/x.png)\"\n\n\n  sed -i 's/[^1]//' README.md\ndone\n```\n",
+		},
+		{
+			name:  "inline code",
+			input: "Write `[text](page.md)` or `![alt](.assets/a.png)` in the wiki.\n",
+		},
+		{
+			name:  "table with code and links",
+			input: "| Syntax | Meaning |\n|--------|---------|\n| `[a](b.md)` | link |\n| `[^1]` | footnote |\n",
+		},
+		{
+			name:  "markdown fence nesting a shell fence",
+			input: "````markdown\n## References\n\n- [Page](Page.md)\n\n```bash\nmake\n```\n````\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			input := tt.input
+
+			// when
+			result := transformContent(input, "claude")
+
+			// then
+			if result != input {
+				t.Errorf("transformContent() changed code\n  got:  %q\n  want: %q", result, input)
+			}
+		})
+	}
+}
+
+func TestApplyEdits(t *testing.T) {
+	tests := []struct {
+		name     string
+		edits    []edit
+		expected string
+	}{
+		{
+			name:     "no edits",
+			expected: "0123456789",
+		},
+		{
+			name:     "edits applied in source order",
+			edits:    []edit{{Start: 8, Stop: 9, Text: "x"}, {Start: 1, Stop: 3}},
+			expected: "034567x9",
+		},
+		{
+			name:     "enclosing edit wins over edits inside it",
+			edits:    []edit{{Start: 3, Stop: 4}, {Start: 2, Stop: 6, Text: "-"}, {Start: 2, Stop: 3}},
+			expected: "01-6789",
+		},
+		{
+			name:     "insertion kept before an edit at the same offset",
+			edits:    []edit{{Start: 5, Stop: 10}, {Start: 5, Stop: 5, Text: "x"}},
+			expected: "01234x",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			source := []byte("0123456789")
+
+			// when
+			result := applyEdits(source, tt.edits)
+
+			// then
+			if result != tt.expected {
+				t.Errorf("applyEdits() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLinkBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "inline link",
+			input:    "[text](page.md) after",
+			expected: "[text](page.md)",
+		},
+		{
+			name:     "nested brackets and code span in label",
+			input:    "[a [b] `]`](page.md) after",
+			expected: "[a [b] `]`](page.md)",
+		},
+		{
+			name:     "image with title containing parentheses",
+			input:    "![alt](a.png \"fig (1)\") after",
+			expected: "![alt](a.png \"fig (1)\")",
+		},
+		{
+			name:     "full reference link",
+			input:    "[text][ref] after",
+			expected: "[text][ref]",
+		},
+		{
+			name:     "shortcut reference link",
+			input:    "[text] after",
+			expected: "[text]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			source := []byte(tt.input)
+
+			// when
+			end := linkEnd(source, labelEnd(source, 0))
+
+			// then
+			if got := string(source[:end]); got != tt.expected {
+				t.Errorf("link bounds = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestCollapseWhitespace(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -248,6 +399,11 @@ func TestCollapseWhitespace(t *testing.T) {
 			input:    "line1\n\n\nline2",
 			expected: "line1\n\nline2\n",
 		},
+		{
+			name:     "blank lines inside fenced code kept",
+			input:    "```python\nimport os\n\n\ndef main(): ...\n```\n\n\n\nafter",
+			expected: "```python\nimport os\n\n\ndef main(): ...\n```\n\nafter\n",
+		},
 		{
 			name:     "trailing whitespace trimmed",
 			input:    "content\n\n\n",
@@ -274,29 +430,40 @@ func TestCollapseWhitespace(t *testing.T) {
 func TestMergeContents(t *testing.T) {
 	tests := []struct {
 		name     string
+		group    RuleGroup
 		input    []string
 		expected string
 	}{
 		{
-			name:     "two contents merged with separator",
-			input:    []string{"# Part 1\n\nContent A.\n", "# Part 2\n\nContent B.\n"},
-			expected: "# Part 1\n\nContent A.\n\n---\n\n# Part 2\n\nContent B.\n",
+			name:     "pages merged under the group title with demoted headings",
+			group:    RuleGroup{Description: "Go standards"},
+			input:    []string{"# Part 1\n\nContent A.\n", "# Part 2\n\n## Details\n\nContent B.\n"},
+			expected: "# Go standards\n\n## Part 1\n\nContent A.\n\n---\n\n## Part 2\n\n### Details\n\nContent B.\n",
 		},
 		{
 			name:     "empty contents skipped",
+			group:    RuleGroup{Description: "Docs"},
 			input:    []string{"Content A.\n", "", "  \n", "Content B.\n"},
-			expected: "Content A.\n\n---\n\nContent B.\n",
+			expected: "# Docs\n\nContent A.\n\n---\n\nContent B.\n",
 		},
 		{
 			name:     "single content no separator",
+			group:    RuleGroup{Description: "Docs"},
 			input:    []string{"# Only Part\n\nContent.\n"},
-			expected: "# Only Part\n\nContent.\n",
+			expected: "# Docs\n\n## Only Part\n\nContent.\n",
 		},
 		{
 			name:     "all empty returns empty",
+			group:    RuleGroup{Description: "Docs"},
 			input:    []string{"", "  "},
 			expected: "",
 		},
+		{
+			name:     "outline lists page and section headings",
+			group:    RuleGroup{Description: "Go standards", Outline: true},
+			input:    []string{"# Go\n\n## Overview\n\nText.\n", "# Go Testing\n\n## Naming\n\n### Deep\n\nText.\n"},
+			expected: "# Go standards\n\n## Contents\n\n- Go\n  - Overview\n- Go Testing\n  - Naming\n\n## Go\n\n### Overview\n\nText.\n\n---\n\n## Go Testing\n\n### Naming\n\n#### Deep\n\nText.\n",
+		},
 	}
 
 	for _, tt := range tests {
@@ -305,7 +472,7 @@ func TestMergeContents(t *testing.T) {
 			input := tt.input
 
 			// when
-			result := mergeContents(input)
+			result := mergeContents(tt.group, input)
 
 			// then
 			if result != tt.expected {
@@ -315,6 +482,45 @@ func TestMergeContents(t *testing.T) {
 	}
 }
 
+func TestDemoteHeadings(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "ATX headings demoted one level",
+			input:    "# Title\n\n## Section\n\n###### Deepest\n",
+			expected: "## Title\n\n### Section\n\n###### Deepest\n",
+		},
+		{
+			name:     "setext headings rewritten as ATX",
+			input:    "Title\n=====\n\nSection\n-------\n\nText.\n",
+			expected: "## Title\n\n### Section\n\nText.\n",
+		},
+		{
+			name:     "comments in fenced code untouched",
+			input:    "# Setup\n\n```bash\n# Install\ngo install ./...\n```\n",
+			expected: "## Setup\n\n```bash\n# Install\ngo install ./...\n```\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			input := tt.input
+
+			// when
+			result := rewrite(input, demoteHeadings)
+
+			// then
+			if result != tt.expected {
+				t.Errorf("demoteHeadings()\n  got:  %q\n  want: %q", result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestTransformContent(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -363,6 +569,11 @@ The [Go Proverbs](https://go-proverbs.github.io/) capture the design philosophy.
 Clean Architecture is key.
 `,
 		},
+		{
+			name:     "image inside internal link removed with the link",
+			input:    "See [![badge](.assets/badge.png)Guide](Guide.md) now.\n",
+			expected: "See Guide now.\n",
+		},
 	}
 
 	for _, tt := range tests {
@@ -371,7 +582,7 @@ Clean Architecture is key.
 			input := tt.input
 
 			// when
-			result := transformContent(input)
+			result := transformContent(input, "claude")
 
 			// then
 			if result != tt.expected {
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+// generatorVersion identifies the generate-ai-rules build in provenance blocks.
+// The workflow sets it with -ldflags "-X main.generatorVersion=<version>".
+var generatorVersion = "dev"
+
+// provenanceNotice closes every provenance block.
+const provenanceNotice = "Generated from the development guide; do not edit manually, changes are overwritten."
+
+// Keys of the provenance block lines.
+const (
+	provenanceGeneratedBy  = "generated-by"
+	provenanceSourceCommit = "source-commit"
+	provenanceSources      = "sources"
+	provenanceContentHash  = "content-sha256"
+)
+
+// errNoProvenance is returned for files that carry no provenance block.
+var errNoProvenance = errors.New("no provenance block")
+
+// commentStyle is how a file format writes a comment spanning several lines.
+type commentStyle struct {
+	open   string // line opening the comment, empty when every line is prefixed instead
+	prefix string // prefix of each line inside the comment
+	close  string // line closing the comment
+}
+
+var (
+	htmlComments     = commentStyle{open: "<!--\n", close: "-->\n"}
+	starlarkComments = commentStyle{prefix: "# "}
+)
+
+// commentStyleFor returns the comment style of a generated file: Starlark for Codex
+// .rules files and HTML for the markdown every other target reads.
+func commentStyleFor(path string) commentStyle {
+	if strings.HasSuffix(path, ".rules") {
+		return starlarkComments
+	}
+	return htmlComments
+}
+
+// provenance records where a generated file came from.
+type provenance struct {
+	Generator    string   // generator version
+	SourceCommit string   // last commit that touched the sources; empty for files without sources
+	Sources      []string // source pages relative to the repository root
+	ContentHash  string   // hex SHA-256 of the file without its provenance block
+}
+
+// format returns the provenance block in the given comment style, followed by a blank line.
+func (p provenance) format(style commentStyle) string {
+	lines := []string{provenanceGeneratedBy + ": generate-ai-rules " + p.Generator}
+	if len(p.Sources) > 0 {
+		lines = append(lines,
+			provenanceSourceCommit+": "+p.SourceCommit,
+			provenanceSources+": "+strings.Join(p.Sources, ", "))
+	}
+	lines = append(lines, provenanceContentHash+": "+p.ContentHash, provenanceNotice)
+
+	var sb strings.Builder
+	sb.WriteString(style.open)
+	for _, line := range lines {
+		sb.WriteString(style.prefix + line + "\n")
+	}
+	sb.WriteString(style.close)
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// provenanceSize returns the largest number of bytes the provenance block of a markdown
+// file with the given sources adds, for targets that must stay within a size limit.
+func provenanceSize(sources []string) int {
+	p := provenance{
+		Generator:    generatorVersion,
+		SourceCommit: strings.Repeat("0", 40),
+		Sources:      sources,
+		ContentHash:  strings.Repeat("0", sha256.Size*2),
+	}
+	return len(p.format(htmlComments))
+}
+
+// contentHash returns the hex SHA-256 of body.
+func contentHash(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
+
+// provenanceOffset returns where the provenance block goes in body: after the front
+// matter and the blank line that follows it, since assistants only read front matter
+// on the first line, or at the top of files without front matter.
+func provenanceOffset(body string) int {
+	_, rest := splitFrontMatter([]byte(body))
+	offset := len(body) - len(rest)
+	if offset > 0 && strings.HasPrefix(string(rest), "\n") {
+		offset++
+	}
+	return offset
+}
+
+// sourceCommits looks up the last commit that touched a set of source pages, caching
+// each answer since most sets are looked up by several targets.
+type sourceCommits struct {
+	sourceDir string
+	cache     map[string]string
+}
+
+func newSourceCommits(sourceDir string) *sourceCommits {
+	return &sourceCommits{sourceDir: sourceDir, cache: make(map[string]string)}
+}
+
+// lookup returns the full SHA of the last commit that touched any of sources, or "unknown"
+// when sourceDir is not a git repository or git is missing.
+func (c *sourceCommits) lookup(sources []string) string {
+	key := strings.Join(sources, "\n")
+	if commit, ok := c.cache[key]; ok {
+		return commit
+	}
+	commit := "unknown"
+	args := append([]string{"-C", c.sourceDir, "log", "-1", "--format=%H", "--"}, sources...)
+	if out, err := exec.Command("git", args...).Output(); err == nil && len(strings.TrimSpace(string(out))) > 0 {
+		commit = strings.TrimSpace(string(out))
+	} else {
+		logger.WithFields(logger.Fields{
+			"source_dir": c.sourceDir,
+			"sources":    sources,
+		}).Debug("could not find the source commit of a generated file")
+	}
+	c.cache[key] = commit
+	return commit
+}
+
+// stampProvenance adds a provenance block to every rendered file. The content hash covers
+// the file as rendered, so verifyProvenance can recompute it once the block is removed.
+func stampProvenance(files []renderedFile, commits *sourceCommits) []renderedFile {
+	stamped := make([]renderedFile, len(files))
+	for i, file := range files {
+		p := provenance{
+			Generator:   generatorVersion,
+			Sources:     file.Sources,
+			ContentHash: contentHash(file.Body),
+		}
+		if len(file.Sources) > 0 {
+			p.SourceCommit = commits.lookup(file.Sources)
+		}
+		offset := provenanceOffset(file.Body)
+		file.Body = file.Body[:offset] + p.format(commentStyleFor(file.Path)) + file.Body[offset:]
+		stamped[i] = file
+	}
+	return stamped
+}
+
+// verifyProvenance checks a generated file against its provenance block. It returns
+// errNoProvenance for files without a block, and an error describing the change for
+// files whose block or content was edited after generation.
+func verifyProvenance(path string, body string) (provenance, error) {
+	style := commentStyleFor(path)
+	start := strings.Index(body, style.open+style.prefix+provenanceGeneratedBy+": ")
+	if start < 0 {
+		return provenance{}, errNoProvenance
+	}
+
+	var p provenance
+	lines := strings.Split(body[start+len(style.open):], "\n")
+	for _, line := range lines {
+		line = strings.TrimPrefix(line, style.prefix)
+		if line == provenanceNotice {
+			break
+		}
+		key, value, _ := strings.Cut(line, ": ")
+		switch key {
+		case provenanceGeneratedBy:
+			p.Generator = strings.TrimPrefix(value, "generate-ai-rules ")
+		case provenanceSourceCommit:
+			p.SourceCommit = value
+		case provenanceSources:
+			p.Sources = strings.Split(value, ", ")
+		case provenanceContentHash:
+			p.ContentHash = value
+		default:
+			return p, fmt.Errorf("unexpected provenance line %q", line)
+		}
+	}
+
+	block := p.format(style)
+	if !strings.HasPrefix(body[start:], block) {
+		return p, errors.New("provenance block was edited")
+	}
+	if contentHash(body[:start]+body[start+len(block):]) != p.ContentHash {
+		return p, errors.New("content was edited after generation")
+	}
+	return p, nil
+}
+
+// verifyGeneratedFiles checks every generated file below dir, such as a repository the
+// rules were installed into, and returns the paths of the files edited after generation
+// with what changed. Files without a provenance block are not generated and are skipped.
+func verifyGeneratedFiles(dir string) (checked int, edited map[string]error, err error) {
+	edited = make(map[string]error)
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(path); ext != ".md" && ext != ".mdc" && ext != ".rules" {
+			return nil
+		}
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		_, verifyErr := verifyProvenance(path, string(body))
+		if errors.Is(verifyErr, errNoProvenance) {
+			return nil
+		}
+		checked++
+		if verifyErr != nil {
+			edited[filepath.ToSlash(rel)] = verifyErr
+		}
+		return nil
+	})
+	return checked, edited, err
+}
+
+// groupSources returns the sources of the groups at indexes, in order and without the
+// pages several groups share, for files that combine several groups.
+func groupSources(groups []RuleGroup, indexes []int) []string {
+	var sources []string
+	seen := make(map[string]bool)
+	for _, i := range indexes {
+		for _, source := range groups[i].Sources {
+			if !seen[source] {
+				seen[source] = true
+				sources = append(sources, source)
+			}
+		}
+	}
+	return sources
+}
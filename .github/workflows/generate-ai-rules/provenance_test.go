@@ -0,0 +1,171 @@
+package main
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStampProvenance(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     renderedFile
+		expected string
+	}{
+		{
+			name: "after the front matter of a markdown rule",
+			file: renderedFile{
+				Path:    "cursor/rules/golang.mdc",
+				Body:    "---\nalwaysApply: true\n---\n\n# Go\n",
+				Sources: []string{"Code-Style/GoLang.md", "Code-Style/GoLang/Testing.md"},
+			},
+			expected: "---\nalwaysApply: true\n---\n\n<!--\ngenerated-by: generate-ai-rules dev\n" +
+				"source-commit: unknown\nsources: Code-Style/GoLang.md, Code-Style/GoLang/Testing.md\n" +
+				"content-sha256: " + contentHash("---\nalwaysApply: true\n---\n\n# Go\n") + "\n" +
+				provenanceNotice + "\n-->\n\n# Go\n",
+		},
+		{
+			name: "at the top of a file without front matter",
+			file: renderedFile{Path: "codex/AGENTS.md", Body: "# Code Style\n", Sources: []string{"Code-Style.md"}},
+			expected: "<!--\ngenerated-by: generate-ai-rules dev\nsource-commit: unknown\nsources: Code-Style.md\n" +
+				"content-sha256: " + contentHash("# Code Style\n") + "\n" + provenanceNotice + "\n-->\n\n# Code Style\n",
+		},
+		{
+			name: "starlark comments without sources",
+			file: renderedFile{Path: "codex/rules/default.rules", Body: "prefix_rule()\n"},
+			expected: "# generated-by: generate-ai-rules dev\n# content-sha256: " + contentHash("prefix_rule()\n") + "\n" +
+				"# " + provenanceNotice + "\n\nprefix_rule()\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			stamped := stampProvenance([]renderedFile{tt.file}, newSourceCommits(t.TempDir()))
+
+			// then
+			if stamped[0].Body != tt.expected {
+				t.Errorf("stamped body\n  got:  %q\n  want: %q", stamped[0].Body, tt.expected)
+			}
+			if _, err := verifyProvenance(tt.file.Path, stamped[0].Body); err != nil {
+				t.Errorf("verifyProvenance() of an untouched file: %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyProvenanceDetectsEdits(t *testing.T) {
+	// given
+	file := renderedFile{Path: "claude/rules/golang.md", Body: "---\npaths:\n  - '**/*.go'\n---\n\n# Go\n", Sources: []string{"Go.md"}}
+	stamped := stampProvenance([]renderedFile{file}, newSourceCommits(t.TempDir()))[0].Body
+
+	tests := []struct {
+		name        string
+		body        string
+		expectError string
+	}{
+		{name: "content appended", body: stamped + "- Extra rule\n", expectError: "content was edited after generation"},
+		{name: "front matter changed", body: strings.Replace(stamped, "**/*.go", "**/*.py", 1), expectError: "content was edited after generation"},
+		{name: "hash changed", body: strings.Replace(stamped, "content-sha256: ", "content-sha256: 0", 1), expectError: "content was edited after generation"},
+		{name: "notice removed", body: strings.Replace(stamped, provenanceNotice+"\n", "", 1), expectError: "unexpected provenance line"},
+		{name: "block reformatted", body: strings.Replace(stamped, "-->\n\n", "-->\n", 1), expectError: "provenance block was edited"},
+		{name: "no block", body: file.Body, expectError: errNoProvenance.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			_, err := verifyProvenance(file.Path, tt.body)
+
+			// then
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("verifyProvenance() error = %v, want error containing %q", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestVerifyGeneratedFiles(t *testing.T) {
+	// given
+	consumerDir := t.TempDir()
+	files := stampProvenance([]renderedFile{
+		{Path: "claude/rules/golang.md", Body: "# Go\n", Sources: []string{"Go.md"}},
+		{Path: "cursor/rules/golang.mdc", Body: "---\nalwaysApply: true\n---\n\n# Go\n", Sources: []string{"Go.md"}},
+		{Path: "codex/rules/default.rules", Body: "prefix_rule()\n"},
+	}, newSourceCommits(consumerDir))
+	writeTestFile(t, consumerDir, ".claude/rules/golang.md", files[0].Body+"- Hand-written rule\n")
+	writeTestFile(t, consumerDir, ".cursor/rules/golang.mdc", files[1].Body)
+	writeTestFile(t, consumerDir, ".codex/rules/default.rules", files[2].Body)
+	writeTestFile(t, consumerDir, "README.md", "# Consumer\n")
+	writeTestFile(t, consumerDir, ".git/rules/ignored.md", files[0].Body+"edited\n")
+
+	// when
+	checked, edited, err := verifyGeneratedFiles(consumerDir)
+
+	// then
+	if err != nil {
+		t.Fatalf("verifyGeneratedFiles() error: %v", err)
+	}
+	if checked != 3 {
+		t.Errorf("checked %d files, want 3", checked)
+	}
+	if len(edited) != 1 || edited[".claude/rules/golang.md"] == nil {
+		t.Errorf("edited = %v, want only .claude/rules/golang.md", edited)
+	}
+}
+
+func TestSourceCommitsLookup(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	// given
+	repoDir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repoDir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+	writeTestFile(t, repoDir, "Go.md", "# Go\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "add Go")
+	goCommit := git("rev-parse", "HEAD")
+	writeTestFile(t, repoDir, "Python.md", "# Python\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "add Python")
+
+	// when
+	commits := newSourceCommits(repoDir)
+
+	// then
+	if commit := commits.lookup([]string{"Go.md"}); commit != goCommit {
+		t.Errorf("lookup(Go.md) = %s, want the commit that last touched it, %s", commit, goCommit)
+	}
+	if commit := newSourceCommits(t.TempDir()).lookup([]string{"Go.md"}); commit != "unknown" {
+		t.Errorf("lookup() outside a repository = %s, want unknown", commit)
+	}
+}
+
+func TestGroupSources(t *testing.T) {
+	// given
+	groups := []RuleGroup{
+		{Name: "yaml", Sources: []string{"Code-Style/YAML.md"}},
+		{Name: "code-style", Sources: []string{"Code-Style.md", "Code-Style/YAML.md"}},
+		{Name: "yaml-checklist", Sources: []string{"Code-Style/YAML.md", "Tests.md"}},
+	}
+
+	// when
+	sources := groupSources(groups, []int{0, 1, 2})
+
+	// then
+	expected := []string{"Code-Style/YAML.md", "Code-Style.md", "Tests.md"}
+	if !reflect.DeepEqual(sources, expected) {
+		t.Errorf("groupSources() = %q, want %q", sources, expected)
+	}
+}
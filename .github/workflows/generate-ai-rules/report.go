@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+// textStats holds the size of a piece of generated text.
+type textStats struct {
+	Bytes  int `json:"bytes"`
+	Words  int `json:"words"`
+	Tokens int `json:"estimated_tokens"`
+}
+
+// groupReport is the size of a single rule group's merged content. When target blocks
+// make the content differ between targets, the largest variant is reported.
+type groupReport struct {
+	Name string `json:"name"`
+	textStats
+	TokenBudget int  `json:"token_budget,omitempty"`
+	OverBudget  bool `json:"over_budget,omitempty"`
+}
+
+// targetReport is the combined size of every file emitted by a target.
+type targetReport struct {
+	Name  string `json:"name"`
+	Files int    `json:"files"`
+	textStats
+}
+
+// budgetReport is the token-cost report written by the -report flag.
+type budgetReport struct {
+	Groups  []groupReport  `json:"groups"`
+	Targets []targetReport `json:"targets"`
+}
+
+// measure returns the byte, word, and estimated token counts of text.
+func measure(text string) textStats {
+	return textStats{
+		Bytes:  len(text),
+		Words:  len(strings.Fields(text)),
+		Tokens: estimateTokens(text),
+	}
+}
+
+// estimateTokens approximates the number of tokens an assistant is billed for text.
+// It averages the two usual rules of thumb for English prose and code: one token per
+// four characters, and four tokens per three words. No real tokenizer is involved,
+// so the result is meant for comparisons and budgets, not for exact accounting.
+func estimateTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	byChars := float64(utf8.RuneCountInString(text)) / 4
+	byWords := float64(len(strings.Fields(text))) * 4 / 3
+	return int((byChars+byWords)/2 + 0.5)
+}
+
+// buildReport measures every rule group and every selected target.
+func buildReport(selected []Target, groups []RuleGroup, contents ruleContents, files []renderedFile) budgetReport {
+	report := budgetReport{}
+	for i, group := range groups {
+		var stats textStats
+		for _, target := range selected {
+			if variant := measure(contents[target.Name()][i]); variant.Tokens > stats.Tokens {
+				stats = variant
+			}
+		}
+		report.Groups = append(report.Groups, groupReport{
+			Name:        group.Name,
+			textStats:   stats,
+			TokenBudget: group.TokenBudget,
+			OverBudget:  group.TokenBudget > 0 && stats.Tokens > group.TokenBudget,
+		})
+	}
+
+	index := make(map[string]int, len(selected))
+	for _, target := range selected {
+		index[target.Name()] = len(report.Targets)
+		report.Targets = append(report.Targets, targetReport{Name: target.Name()})
+	}
+	for _, file := range files {
+		i, ok := index[file.Target]
+		if !ok {
+			continue
+		}
+		stats := measure(file.Body)
+		report.Targets[i].Files++
+		report.Targets[i].Bytes += stats.Bytes
+		report.Targets[i].Words += stats.Words
+		report.Targets[i].Tokens += stats.Tokens
+	}
+	return report
+}
+
+// checkTokenBudgets logs every group over its declared token budget and returns how many there are.
+func checkTokenBudgets(report budgetReport) int {
+	var count int
+	for _, group := range report.Groups {
+		if !group.OverBudget {
+			continue
+		}
+		logger.WithFields(logger.Fields{
+			"group":            group.Name,
+			"estimated_tokens": group.Tokens,
+			"token_budget":     group.TokenBudget,
+		}).Error("rule group exceeds its token budget")
+		count++
+	}
+	return count
+}
+
+// writeReport writes the report as JSON or Markdown, depending on the file extension.
+func writeReport(path string, report budgetReport) error {
+	var data []byte
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		encoded, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding report: %w", err)
+		}
+		data = append(encoded, '\n')
+	case ".md":
+		data = []byte(formatReportMarkdown(report))
+	default:
+		return fmt.Errorf("unsupported report format %q (use .json or .md)", ext)
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("creating directory %s: %w", dir, err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	logger.WithFields(logger.Fields{
+		"path":  path,
+		"bytes": len(data),
+	}).Debug("wrote budget report")
+	return nil
+}
+
+// formatReportMarkdown renders the report as Markdown tables.
+func formatReportMarkdown(report budgetReport) string {
+	var sb strings.Builder
+	sb.WriteString("# Rule Budget Report\n\n")
+	sb.WriteString("Token counts are estimates (see `estimateTokens` in `generate-ai-rules`).\n\n")
+
+	sb.WriteString("## Rule Groups\n\n")
+	sb.WriteString("| Group | Bytes | Words | Estimated Tokens | Token Budget |\n")
+	sb.WriteString("|-------|------:|------:|-----------------:|-------------:|\n")
+	for _, group := range report.Groups {
+		budget := "-"
+		if group.TokenBudget > 0 {
+			budget = fmt.Sprintf("%d", group.TokenBudget)
+			if group.OverBudget {
+				budget += " (exceeded)"
+			}
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %s |\n",
+			group.Name, group.Bytes, group.Words, group.Tokens, budget))
+	}
+
+	sb.WriteString("\n## Targets\n\n")
+	sb.WriteString("| Target | Files | Bytes | Words | Estimated Tokens |\n")
+	sb.WriteString("|--------|------:|------:|------:|-----------------:|\n")
+	for _, target := range report.Targets {
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d |\n",
+			target.Name, target.Files, target.Bytes, target.Words, target.Tokens))
+	}
+	return sb.String()
+}
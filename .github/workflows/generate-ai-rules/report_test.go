@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+	}{
+		{
+			name:     "empty text has no tokens",
+			input:    "",
+			expected: 0,
+		},
+		{
+			name:     "averages the character and word heuristics",
+			input:    "Use kebab-case for file names.",
+			expected: 7, // 30 chars / 4 = 7.5, 5 words * 4/3 = 6.67
+		},
+		{
+			name:     "counts characters rather than bytes",
+			input:    "ação ação",
+			expected: 2, // 9 chars / 4 = 2.25, 2 words * 4/3 = 2.67
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			input := tt.input
+
+			// when
+			result := estimateTokens(input)
+
+			// then
+			if result != tt.expected {
+				t.Errorf("estimateTokens(%q) = %d, want %d", input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	// given
+	selected := []Target{claudeTarget{}, aiderTarget{}}
+	groups := []RuleGroup{
+		{Name: "code-style", Description: "Code style", TokenBudget: 2},
+		{Name: "golang", Description: "Go", Globs: GlobList{"**/*.go"}, TokenBudget: 100},
+	}
+	contents := ruleContents{
+		"claude": {"# Code Style\n\nUse kebab-case for file names.\n", "# Go\n"},
+		"aider":  {"# Code Style\n\nUse kebab-case for file names.\n\nAider only.\n", "# Go\n"},
+	}
+	files, _ := renderAllRules(selected, groups, contents)
+
+	// when
+	report := buildReport(selected, groups, contents, files)
+
+	// then
+	if len(report.Groups) != 2 || len(report.Targets) != 2 {
+		t.Fatalf("report has %d groups and %d targets, want 2 and 2", len(report.Groups), len(report.Targets))
+	}
+	if got := report.Groups[0]; got.Bytes != len(contents["aider"][0]) || got.Words != 10 || !got.OverBudget {
+		t.Errorf("code-style report = %+v, want the larger Aider variant over budget", got)
+	}
+	if report.Groups[1].OverBudget {
+		t.Errorf("golang report = %+v, want within budget", report.Groups[1])
+	}
+	if got := report.Targets[0]; got.Name != "claude" || got.Files != 2 {
+		t.Errorf("claude report = %+v, want 2 files", got)
+	}
+	if got := report.Targets[1]; got.Name != "aider" || got.Files != 1 || got.Tokens == 0 {
+		t.Errorf("aider report = %+v, want 1 file with tokens", got)
+	}
+	if count := checkTokenBudgets(report); count != 1 {
+		t.Errorf("checkTokenBudgets() = %d, want 1", count)
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	report := budgetReport{
+		Groups: []groupReport{
+			{Name: "code-style", textStats: textStats{Bytes: 40, Words: 8, Tokens: 10}, TokenBudget: 5, OverBudget: true},
+		},
+		Targets: []targetReport{
+			{Name: "claude", Files: 1, textStats: textStats{Bytes: 60, Words: 12, Tokens: 15}},
+		},
+	}
+
+	t.Run("JSON report", func(t *testing.T) {
+		// given
+		path := filepath.Join(t.TempDir(), "report.json")
+
+		// when
+		err := writeReport(path, report)
+
+		// then
+		if err != nil {
+			t.Fatalf("writeReport() error: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading report: %v", err)
+		}
+		var decoded map[string][]map[string]any
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("report is not valid JSON: %v", err)
+		}
+		group := decoded["groups"][0]
+		if group["name"] != "code-style" || group["estimated_tokens"] != float64(10) || group["over_budget"] != true {
+			t.Errorf("groups[0] = %v, want flattened code-style stats", group)
+		}
+		if decoded["targets"][0]["files"] != float64(1) {
+			t.Errorf("targets[0] = %v, want 1 file", decoded["targets"][0])
+		}
+	})
+
+	t.Run("Markdown report", func(t *testing.T) {
+		// given
+		path := filepath.Join(t.TempDir(), "reports", "report.md")
+
+		// when
+		err := writeReport(path, report)
+
+		// then
+		if err != nil {
+			t.Fatalf("writeReport() error: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading report: %v", err)
+		}
+		for _, want := range []string{"| code-style | 40 | 8 | 10 | 5 (exceeded) |", "| claude | 1 | 60 | 12 | 15 |"} {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("report missing row %q:\n%s", want, data)
+			}
+		}
+	})
+
+	t.Run("unsupported extension", func(t *testing.T) {
+		// given
+		path := filepath.Join(t.TempDir(), "report.txt")
+
+		// when
+		err := writeReport(path, report)
+
+		// then
+		if err == nil || !strings.Contains(err.Error(), "unsupported report format") {
+			t.Errorf("writeReport() error = %v, want unsupported report format", err)
+		}
+	})
+}
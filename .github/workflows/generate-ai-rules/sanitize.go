@@ -0,0 +1,130 @@
+package main
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// defaultAllowedHTML lists the HTML elements kept in the rules by default: they carry
+// meaning an assistant can use, unlike layout and badges.
+const defaultAllowedHTML = "kbd,sub,sup"
+
+var (
+	// htmlCommentRegex matches an HTML comment, possibly spanning several lines.
+	htmlCommentRegex = regexp.MustCompile(`(?s)<!--.*?-->`)
+	// htmlDroppedRegex matches elements removed together with their content.
+	htmlDroppedRegex = regexp.MustCompile(`(?is)<(script|style)\b[^>]*>.*?</(?:script|style)\s*>`)
+	// htmlSummaryRegex matches the visible title of a <details> block.
+	htmlSummaryRegex = regexp.MustCompile(`(?is)<summary\b[^>]*>(.*?)</summary\s*>`)
+	// htmlHeadingRegex matches HTML headings, such as the centered <h1> of a README.
+	htmlHeadingRegex = regexp.MustCompile(`(?is)<h([1-6])\b[^>]*>(.*?)</h[1-6]\s*>`)
+	// htmlTagRegex matches a single opening, closing, or self-closing tag.
+	htmlTagRegex = regexp.MustCompile(`</?([a-zA-Z][a-zA-Z0-9-]*)\b[^>]*>`)
+)
+
+// htmlSanitizer removes presentation-only HTML from source pages: comments, badges and
+// other images, <script> and <style>, and the wrappers of layout elements such as <p align>,
+// <div>, and <details>, whose text is kept. Elements in the allowlist are kept as written.
+// HTML inside code blocks and code spans is not HTML to the parser, so it is never touched.
+type htmlSanitizer struct {
+	allowed []string // lowercase element names
+}
+
+// newHTMLSanitizer returns a sanitizer keeping the elements of a comma-separated allowlist.
+func newHTMLSanitizer(allowlist string) htmlSanitizer {
+	var allowed []string
+	for _, name := range strings.Split(allowlist, ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			allowed = append(allowed, name)
+		}
+	}
+	return htmlSanitizer{allowed: allowed}
+}
+
+// visit is the sanitizer's visitor. Comment markers (see markers.go) are left to their own visitors.
+func (s htmlSanitizer) visit(node ast.Node, source []byte) []edit {
+	start, stop, raw, ok := htmlSource(node, source)
+	if !ok || markerRegex.MatchString(strings.TrimSpace(raw)) {
+		return nil
+	}
+	if node.Kind() == ast.KindRawHTML {
+		if s.keeps(raw) {
+			return nil
+		}
+		return []edit{deleteInline(source, start, stop)}
+	}
+
+	flattened := s.flatten(raw)
+	if flattened == strings.TrimSpace(raw) {
+		return nil
+	}
+	if flattened == "" {
+		return []edit{{Start: start, Stop: stop}}
+	}
+	return []edit{{Start: start, Stop: stop, Text: flattened + "\n"}}
+}
+
+// keeps reports whether a single inline tag is an allowed element.
+func (s htmlSanitizer) keeps(tag string) bool {
+	match := htmlTagRegex.FindStringSubmatch(tag)
+	return match != nil && match[0] == tag && !strings.EqualFold(match[1], "img") &&
+		slices.Contains(s.allowed, strings.ToLower(match[1]))
+}
+
+// flatten reduces an HTML block to the text it shows: comments, scripts, styles, and images
+// disappear, summaries become bold, headings become markdown headings, and every other tag
+// outside the allowlist is removed while its text stays. Blank lines are dropped.
+func (s htmlSanitizer) flatten(raw string) string {
+	raw = htmlCommentRegex.ReplaceAllString(raw, "")
+	raw = htmlDroppedRegex.ReplaceAllString(raw, "")
+	raw = htmlSummaryRegex.ReplaceAllStringFunc(raw, func(summary string) string {
+		return "**" + strings.TrimSpace(htmlSummaryRegex.FindStringSubmatch(summary)[1]) + "**\n"
+	})
+	raw = htmlHeadingRegex.ReplaceAllStringFunc(raw, func(heading string) string {
+		match := htmlHeadingRegex.FindStringSubmatch(heading)
+		return "\n" + strings.Repeat("#", int(match[1][0]-'0')) + " " + strings.TrimSpace(match[2]) + "\n"
+	})
+	raw = htmlTagRegex.ReplaceAllStringFunc(raw, func(tag string) string {
+		if s.keeps(tag) {
+			return tag
+		}
+		return ""
+	})
+
+	var lines []string
+	for _, line := range strings.Split(raw, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// htmlSource returns the source range and text of an HTML block or of inline raw HTML.
+// The range of a block covers its whole lines, including the last line break.
+func htmlSource(node ast.Node, source []byte) (int, int, string, bool) {
+	switch html := node.(type) {
+	case *ast.HTMLBlock:
+		lines := html.Lines()
+		if lines.Len() == 0 {
+			return 0, 0, "", false
+		}
+		start, stop := blockStart(source, html), lines.At(lines.Len()-1).Stop
+		raw := string(source[lines.At(0).Start:stop])
+		if html.HasClosure() {
+			stop = html.ClosureLine.Stop
+			raw += string(html.ClosureLine.Value(source))
+		}
+		return start, stop, raw, true
+	case *ast.RawHTML:
+		if html.Segments.Len() == 0 {
+			return 0, 0, "", false
+		}
+		start, stop := html.Segments.At(0).Start, html.Segments.At(html.Segments.Len()-1).Stop
+		return start, stop, string(source[start:stop]), true
+	}
+	return 0, 0, "", false
+}
@@ -0,0 +1,83 @@
+package main
+
+import "testing"
+
+func TestHTMLSanitizer(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "comment block removed",
+			input:    "<!-- in GitHub Wiki each file name (page name) is an anchor -->\n# Home\n\nText.\n",
+			expected: "# Home\n\nText.\n",
+		},
+		{
+			name:     "multi-line comment hiding markdown removed",
+			input:    "Intro.\n\n<!--\n## Optional\n\n| a | b |\n-->\n\nNext.\n",
+			expected: "Intro.\n\nNext.\n",
+		},
+		{
+			name:     "inline comment removed",
+			input:    "Use tabs <!-- for now -->in Go.\n",
+			expected: "Use tabs in Go.\n",
+		},
+		{
+			name:     "badges removed",
+			input:    "<p align=\"center\">\n    <a href=\"https://example.com\">\n        <img src=\"https://img.shields.io/badge/go-blue\" alt=\"Go\"/></a>\n</p>\n\nText.\n",
+			expected: "Text.\n",
+		},
+		{
+			name:     "layout wrappers flattened to their text",
+			input:    "<h1 align=\"center\">Guide</h1>\n<p align=\"center\">\n  Team standards.\n</p>\n\nText.\n",
+			expected: "# Guide\nTeam standards.\n\nText.\n",
+		},
+		{
+			name:     "details block flattened",
+			input:    "<details>\n<summary>Why rebase?</summary>\n\nHistory stays linear.\n\n</details>\n\nText.\n",
+			expected: "**Why rebase?**\n\nHistory stays linear.\n\nText.\n",
+		},
+		{
+			name:     "allowed elements kept",
+			input:    "Press <kbd>Ctrl</kbd>+<kbd>C</kbd>, then read note<sup>1</sup>.<br>\n",
+			expected: "Press <kbd>Ctrl</kbd>+<kbd>C</kbd>, then read note<sup>1</sup>.\n",
+		},
+		{
+			name:     "HTML in code untouched",
+			input:    "Write `<br>` or:\n\n```html\n<!-- comment -->\n<p>text</p>\n```\n",
+			expected: "Write `<br>` or:\n\n```html\n<!-- comment -->\n<p>text</p>\n```\n",
+		},
+		{
+			name:     "markers left to their visitors",
+			input:    "<!-- ai:only -->\nFor assistants.\n<!-- /ai:only -->\n",
+			expected: "For assistants.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			result := transformContent(tt.input, "claude")
+
+			// then
+			if result != tt.expected {
+				t.Errorf("sanitized content:\ngot:  %q\nwant: %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHTMLSanitizerAllowlist(t *testing.T) {
+	// given
+	input := "Press <kbd>Esc</kbd> and see <abbr title=\"Pull Request\">PR</abbr>.\n"
+
+	// when
+	result := parsePage(input, newHTMLSanitizer(" ABBR ,").visit).render()
+
+	// then
+	expected := "Press Esc and see <abbr title=\"Pull Request\">PR</abbr>.\n"
+	if result != expected {
+		t.Errorf("sanitized with a custom allowlist:\ngot:  %q\nwant: %q", result, expected)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// allTargets is the -targets value that selects every registered target.
+const allTargets = "all"
+
+// errBudgetExceeded is returned by targets whose output is still over a size budget
+// after rendering. It only fails the run in strict mode.
+var errBudgetExceeded = errors.New("size budget exceeded")
+
+// Target is an AI assistant output format. Adding a new assistant means implementing
+// this interface and registering the implementation in targets.
+type Target interface {
+	// Name returns the identifier used by the -targets flag and in logs.
+	Name() string
+	// Paths returns the files and directories (relative to the output directory) owned by the target.
+	Paths() []string
+	// Reference returns how a rule of this target points to another rule group,
+	// used in place of links to pages that belong to that group.
+	Reference(group RuleGroup) string
+	// RenderGroup renders the files produced for a single rule group.
+	RenderGroup(group RuleGroup, content string) []renderedFile
+	// RenderAggregate renders the files that combine every rule group.
+	// Budget violations are reported by wrapping errBudgetExceeded.
+	RenderAggregate(groups []RuleGroup, contents []string) ([]renderedFile, error)
+}
+
+// ruleContents holds the merged content of every rule group as seen by each target,
+// keyed by target name and indexed like the rule groups. Content differs between
+// targets only through target blocks in the source pages.
+type ruleContents map[string][]string
+
+// targets returns the registry of every supported output target, in output order.
+func targets() []Target {
+	return []Target{
+		claudeTarget{},
+		cursorTarget{},
+		copilotTarget{},
+		codexTarget{},
+		windsurfTarget{},
+		geminiTarget{},
+		aiderTarget{},
+		continueTarget{},
+	}
+}
+
+// selectTargets returns the registered targets named in a comma-separated list, in registry order.
+// The special value "all" (or an empty list) selects every registered target.
+func selectTargets(list string) ([]Target, error) {
+	registry := targets()
+	if strings.TrimSpace(list) == "" || list == allTargets {
+		return registry, nil
+	}
+
+	known := make(map[string]bool, len(registry))
+	for _, target := range registry {
+		known[target.Name()] = true
+	}
+	wanted := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if !known[name] {
+			return nil, fmt.Errorf("unknown target %q (available: %s)", name, strings.Join(targetNames(registry), ", "))
+		}
+		wanted[name] = true
+	}
+
+	var selected []Target
+	for _, target := range registry {
+		if wanted[target.Name()] {
+			selected = append(selected, target)
+		}
+	}
+	return selected, nil
+}
+
+// targetNames returns the names of the given targets.
+func targetNames(list []Target) []string {
+	names := make([]string, len(list))
+	for i, target := range list {
+		names[i] = target.Name()
+	}
+	return names
+}
+
+// targetPaths returns every output path owned by the given targets.
+func targetPaths(list []Target) []string {
+	var paths []string
+	for _, target := range list {
+		paths = append(paths, target.Paths()...)
+	}
+	return paths
+}
+
+// claudeTarget emits one Claude Code rule file per group, or a skill for groups loaded on demand.
+type claudeTarget struct{}
+
+func (claudeTarget) Name() string    { return "claude" }
+func (claudeTarget) Paths() []string { return []string{"claude/rules", "claude/skills"} }
+
+// Reference names skills instead of importing them, since an import would load them up front.
+func (claudeTarget) Reference(group RuleGroup) string {
+	if group.onDemand() {
+		return "the " + group.Name + " skill"
+	}
+	return "@claude/rules/" + group.Name + ".md"
+}
+
+func (claudeTarget) RenderGroup(group RuleGroup, content string) []renderedFile {
+	return []renderedFile{renderClaude(group, content)}
+}
+
+func (claudeTarget) RenderAggregate([]RuleGroup, []string) ([]renderedFile, error) { return nil, nil }
+
+// cursorTarget emits one Cursor rule file per group.
+type cursorTarget struct{}
+
+func (cursorTarget) Name() string    { return "cursor" }
+func (cursorTarget) Paths() []string { return []string{"cursor/rules"} }
+
+func (cursorTarget) Reference(group RuleGroup) string { return "@" + group.Name }
+
+func (cursorTarget) RenderGroup(group RuleGroup, content string) []renderedFile {
+	return []renderedFile{renderCursor(group, content)}
+}
+
+func (cursorTarget) RenderAggregate([]RuleGroup, []string) ([]renderedFile, error) { return nil, nil }
+
+// copilotTarget emits one GitHub Copilot instruction file per group.
+type copilotTarget struct{}
+
+func (copilotTarget) Name() string    { return "copilot" }
+func (copilotTarget) Paths() []string { return []string{"copilot/instructions"} }
+
+func (copilotTarget) Reference(group RuleGroup) string {
+	return "see the " + group.Name + " instructions"
+}
+
+func (copilotTarget) RenderGroup(group RuleGroup, content string) []renderedFile {
+	return []renderedFile{renderCopilot(group, content)}
+}
+
+func (copilotTarget) RenderAggregate([]RuleGroup, []string) ([]renderedFile, error) { return nil, nil }
+
+// codexTarget emits AGENTS.md files split within the Codex size limit plus the command execution policy file.
+type codexTarget struct{}
+
+func (codexTarget) Name() string    { return "codex" }
+func (codexTarget) Paths() []string { return []string{"codex"} }
+
+func (codexTarget) Reference(group RuleGroup) string { return "see the " + group.Name + " rules" }
+
+func (codexTarget) RenderGroup(RuleGroup, string) []renderedFile { return nil }
+
+func (codexTarget) RenderAggregate(groups []RuleGroup, contents []string) ([]renderedFile, error) {
+	files, err := renderCodex(groups, contents)
+	return append(files, renderCodexRules()), err
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSelectTargets(t *testing.T) {
+	tests := []struct {
+		name        string
+		list        string
+		expected    []string
+		expectError string
+	}{
+		{
+			name:     "all selects every registered target",
+			list:     "all",
+			expected: targetNames(targets()),
+		},
+		{
+			name:     "empty list selects every registered target",
+			list:     "",
+			expected: targetNames(targets()),
+		},
+		{
+			name:     "subset keeps registry order",
+			list:     "codex, claude",
+			expected: []string{"claude", "codex"},
+		},
+		{
+			name:        "unknown target rejected",
+			list:        "claude,emacs",
+			expectError: `unknown target "emacs"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			list := tt.list
+
+			// when
+			selected, err := selectTargets(list)
+
+			// then
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Fatalf("selectTargets(%q) error = %v, want error containing %q", list, err, tt.expectError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectTargets(%q) error: %v", list, err)
+			}
+			if names := targetNames(selected); !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("selectTargets(%q) = %v, want %v", list, names, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderAllRulesOnlyEmitsSelectedTargets(t *testing.T) {
+	// given
+	selected := []Target{cursorTarget{}}
+	groups := []RuleGroup{
+		{Name: "code-style", Description: "Code style"},
+		{Name: "empty", Description: "Empty group"},
+	}
+	contents := []string{"# Code Style\n", ""}
+
+	// when
+	files, errs := renderAllRules(selected, groups, sameContents(selected, contents))
+
+	// then
+	if len(errs) != 0 {
+		t.Fatalf("renderAllRules() errors: %v", errs)
+	}
+	if len(files) != 1 {
+		t.Fatalf("renderAllRules() returned %d files, want 1", len(files))
+	}
+	if files[0].Target != "cursor" || files[0].Path != "cursor/rules/code-style.mdc" {
+		t.Errorf("renderAllRules() = %+v, want the Cursor code-style rule", files[0])
+	}
+}
+
+func TestTargetPathsCoverRenderedFiles(t *testing.T) {
+	// given
+	groups := []RuleGroup{
+		{Name: "code-style", Description: "Code style"},
+		{Name: "golang", Description: "Go", Globs: GlobList{"**/*.go"}},
+		{Name: "docs", Description: "Docs", Globs: GlobList{"docs/**/*.md"}},
+		{Name: "bulk-operations", Description: "Bulk operations", Activation: activationAgentRequested},
+	}
+	contents := []string{"# Code Style\n", "# Go\n", "# Docs\n", "# Bulk\n"}
+
+	for _, target := range targets() {
+		t.Run(target.Name(), func(t *testing.T) {
+			// when
+			files, _ := renderAllRules([]Target{target}, groups, sameContents([]Target{target}, contents))
+
+			// then
+			for _, file := range files {
+				covered := false
+				for _, owned := range target.Paths() {
+					if file.Path == owned || strings.HasPrefix(file.Path, owned+"/") {
+						covered = true
+					}
+				}
+				if !covered {
+					t.Errorf("%s is not covered by %s paths %v", file.Path, target.Name(), target.Paths())
+				}
+			}
+		})
+	}
+}
+
+// sameContents returns rule contents in which every target sees the same group contents.
+func sameContents(list []Target, contents []string) ruleContents {
+	result := make(ruleContents, len(list))
+	for _, target := range list {
+		result[target.Name()] = contents
+	}
+	return result
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+// windsurfMaxChars is the per-file character limit Windsurf applies to rule files.
+const windsurfMaxChars = 12000
+
+// windsurfTarget emits one Windsurf rule file per group, split into parts when
+// a group exceeds the Windsurf character limit.
+type windsurfTarget struct{}
+
+func (windsurfTarget) Name() string    { return "windsurf" }
+func (windsurfTarget) Paths() []string { return []string{"windsurf/rules"} }
+
+func (windsurfTarget) Reference(group RuleGroup) string { return "see the " + group.Name + " rule" }
+
+func (windsurfTarget) RenderGroup(group RuleGroup, content string) []renderedFile {
+	return renderWindsurf(group, content)
+}
+
+// RenderAggregate writes no file of its own; it reports the parts that are still over the
+// character limit once split, such as one holding a single oversized code block.
+func (windsurfTarget) RenderAggregate(groups []RuleGroup, contents []string) ([]renderedFile, error) {
+	var errs []error
+	for i, group := range groups {
+		if contents[i] == "" {
+			continue
+		}
+		_, parts, overhead := splitWindsurf(group, contents[i])
+		for n, part := range parts {
+			if size := utf8.RuneCountInString(part) + overhead; size > windsurfMaxChars {
+				errs = append(errs, fmt.Errorf("%w: %s is %d characters (limit %d)",
+					errBudgetExceeded, windsurfPath(group, n), size, windsurfMaxChars))
+			}
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// windsurfBoundaries are where content over the character limit is split, coarsest first:
+// H2 headings, H3 headings, then paragraphs.
+var windsurfBoundaries = []func(line string, previous string) bool{
+	func(line string, _ string) bool { return strings.HasPrefix(line, "## ") },
+	func(line string, _ string) bool { return strings.HasPrefix(line, "### ") },
+	func(line string, previous string) bool {
+		return strings.TrimSpace(previous) == "" && strings.TrimSpace(line) != ""
+	},
+}
+
+// renderWindsurf renders a group as windsurf/rules/<name>.md, split as splitWindsurf does
+// into <name>.md, <name>-2.md, ... with the same frontmatter, so every part keeps the
+// group's trigger.
+func renderWindsurf(group RuleGroup, content string) []renderedFile {
+	frontmatter, parts, _ := splitWindsurf(group, content)
+	if len(parts) > 1 {
+		logger.WithFields(logger.Fields{
+			"group":       group.Name,
+			"size_chars":  utf8.RuneCountInString(content),
+			"limit_chars": windsurfMaxChars,
+			"parts":       len(parts),
+		}).Warn("split Windsurf rule exceeding the character limit")
+	}
+
+	files := make([]renderedFile, len(parts))
+	for i, part := range parts {
+		files[i] = renderedFile{
+			Target: "windsurf",
+			Path:   windsurfPath(group, i),
+			Body:   frontmatter + part,
+		}
+	}
+	return files
+}
+
+// splitWindsurf returns the frontmatter of a group's Windsurf rules and its content in
+// parts, with the characters each part adds to its content. Content over windsurfMaxChars
+// is split at the coarsest windsurfBoundaries that make it fit, leaving room in every part
+// for the provenance block added once the files are rendered.
+func splitWindsurf(group RuleGroup, content string) (frontmatter string, parts []string, overhead int) {
+	frontmatter = formatWindsurfFrontmatter(group.Description, group.Globs, group.activation())
+	overhead = utf8.RuneCountInString(frontmatter) + provenanceSize(group.Sources)
+	budget := windsurfMaxChars - overhead
+	if utf8.RuneCountInString(content) <= budget {
+		return frontmatter, []string{content}, overhead
+	}
+	return frontmatter, packSections(splitToFit(content, budget, windsurfBoundaries), budget), overhead
+}
+
+// windsurfPath returns the path of the part at index of a group's Windsurf rules.
+func windsurfPath(group RuleGroup, index int) string {
+	if index == 0 {
+		return "windsurf/rules/" + group.Name + ".md"
+	}
+	return fmt.Sprintf("windsurf/rules/%s-%d.md", group.Name, index+1)
+}
+
+// windsurfFrontmatter is the front matter of a Windsurf rule file.
+type windsurfFrontmatter struct {
+	Trigger     string `yaml:"trigger"` // always_on, glob, model_decision, or manual
+	Description string `yaml:"description"`
+	Globs       string `yaml:"globs,omitempty"` // comma-separated, as Windsurf reads them
+}
+
+// formatWindsurfFrontmatter returns the frontmatter string for a Windsurf rule file, mapping
+// each activation to the Windsurf trigger of the same meaning.
+func formatWindsurfFrontmatter(description string, globs GlobList, activation string) string {
+	frontmatter := windsurfFrontmatter{Trigger: "always_on", Description: description}
+	switch activation {
+	case activationAgentRequested:
+		frontmatter.Trigger = "model_decision"
+	case activationManual:
+		frontmatter.Trigger = "manual"
+	case activationGlob:
+		frontmatter.Trigger = "glob"
+		frontmatter.Globs = strings.Join(globs.translate(windsurfGlobs), ",")
+	}
+	return formatFrontmatter(frontmatter)
+}
+
+// splitToFit splits content at the first of boundaries, then splits again every section
+// still over budget at the next one. Sections that fit are left whole.
+func splitToFit(content string, budget int, boundaries []func(line string, previous string) bool) []string {
+	if utf8.RuneCountInString(content) <= budget || len(boundaries) == 0 {
+		return []string{content}
+	}
+	var sections []string
+	for _, section := range splitBefore(content, boundaries[0]) {
+		sections = append(sections, splitToFit(section, budget, boundaries[1:])...)
+	}
+	return sections
+}
+
+// splitBefore splits markdown content before every line outside fenced code blocks for
+// which boundary, given the line and the one before it, returns true.
+func splitBefore(content string, boundary func(line string, previous string) bool) []string {
+	var sections []string
+	var current strings.Builder
+	inFence := false
+	previous := ""
+	for _, line := range strings.SplitAfter(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		fence := strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+		if !inFence && boundary(line, previous) && current.Len() > 0 {
+			sections = append(sections, current.String())
+			current.Reset()
+		}
+		if fence {
+			inFence = !inFence
+		}
+		current.WriteString(line)
+		previous = line
+	}
+	if current.Len() > 0 {
+		sections = append(sections, current.String())
+	}
+	return sections
+}
+
+// packSections greedily combines consecutive sections into parts of at most budget characters.
+// A single section larger than the budget becomes its own part.
+func packSections(sections []string, budget int) []string {
+	var parts []string
+	var current strings.Builder
+	for _, section := range sections {
+		if current.Len() > 0 && utf8.RuneCountInString(current.String())+utf8.RuneCountInString(section) > budget {
+			parts = append(parts, strings.TrimRight(current.String(), "\n")+"\n")
+			current.Reset()
+		}
+		current.WriteString(section)
+	}
+	if current.Len() > 0 {
+		parts = append(parts, strings.TrimRight(current.String(), "\n")+"\n")
+	}
+	return parts
+}
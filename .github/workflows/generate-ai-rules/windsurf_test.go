@@ -0,0 +1,214 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestFormatWindsurfFrontmatter(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		globs       GlobList
+		activation  string
+		expected    string
+	}{
+		{
+			name:        "glob-triggered with globs",
+			description: "Go language coding standards",
+			globs:       GlobList{"**/*.go"},
+			activation:  activationGlob,
+			expected:    "---\ntrigger: 'glob'\ndescription: 'Go language coding standards'\nglobs: '**/*.go'\n---\n\n",
+		},
+		{
+			name:        "always on without globs",
+			description: "General code style conventions",
+			globs:       nil,
+			activation:  activationAlways,
+			expected:    "---\ntrigger: 'always_on'\ndescription: 'General code style conventions'\n---\n\n",
+		},
+		{
+			name:        "model decision when agent-requested",
+			description: "Bulk operations",
+			activation:  activationAgentRequested,
+			expected:    "---\ntrigger: 'model_decision'\ndescription: 'Bulk operations'\n---\n\n",
+		},
+		{
+			name:        "manual",
+			description: "Release checklist",
+			activation:  activationManual,
+			expected:    "---\ntrigger: 'manual'\ndescription: 'Release checklist'\n---\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			description := tt.description
+			globs := tt.globs
+			activation := tt.activation
+
+			// when
+			result := formatWindsurfFrontmatter(description, globs, activation)
+
+			// then
+			if result != tt.expected {
+				t.Errorf("formatWindsurfFrontmatter(%q, %q, %q)\n  got:  %q\n  want: %q", description, globs, activation, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderWindsurf(t *testing.T) {
+	// given
+	group := RuleGroup{Name: "golang", Description: "Go standards", Globs: GlobList{"**/*.go"}}
+	content := "# Go\n\nUse gofmt.\n"
+
+	// when
+	files := renderWindsurf(group, content)
+
+	// then
+	if len(files) != 1 {
+		t.Fatalf("renderWindsurf() returned %d files, want 1", len(files))
+	}
+	if files[0].Path != "windsurf/rules/golang.md" {
+		t.Errorf("path = %q, want windsurf/rules/golang.md", files[0].Path)
+	}
+	if !strings.HasPrefix(files[0].Body, "---\ntrigger: 'glob'\n") || !strings.HasSuffix(files[0].Body, content) {
+		t.Errorf("unexpected body:\n%s", files[0].Body)
+	}
+}
+
+func TestRenderWindsurfSplitsOversizedGroup(t *testing.T) {
+	// given
+	group := RuleGroup{Name: "architecture", Description: "Architecture"}
+	section := strings.Repeat("Keep layers independent. ", 200) + "\n\n"
+	var sb strings.Builder
+	sb.WriteString("# Architecture\n\n")
+	for i := 0; i < 4; i++ {
+		sb.WriteString("## Section\n\n```markdown\n## not a heading\n```\n\n")
+		sb.WriteString(section)
+	}
+	content := sb.String()
+
+	// when
+	files := renderWindsurf(group, content)
+
+	// then
+	if len(files) < 2 {
+		t.Fatalf("renderWindsurf() returned %d files, want the group to be split", len(files))
+	}
+	if files[1].Path != "windsurf/rules/architecture-2.md" {
+		t.Errorf("second part path = %q, want windsurf/rules/architecture-2.md", files[1].Path)
+	}
+	var total int
+	for _, file := range files {
+		if size := utf8.RuneCountInString(file.Body); size > windsurfMaxChars {
+			t.Errorf("%s has %d characters, limit is %d", file.Path, size, windsurfMaxChars)
+		}
+		if !strings.HasPrefix(file.Body, "---\ntrigger: 'always_on'\n") {
+			t.Errorf("%s should repeat the frontmatter", file.Path)
+		}
+		total += strings.Count(file.Body, "## Section")
+	}
+	if total != 4 {
+		t.Errorf("parts contain %d sections, want 4", total)
+	}
+}
+
+func TestRenderWindsurfSplitsOversizedSectionsFurther(t *testing.T) {
+	// given
+	group := RuleGroup{Name: "golang", Description: "Go standards"}
+	paragraph := strings.Repeat("Wrap errors with context. ", 100) + "\n\n"
+	content := "# Go\n\n## Errors\n\n### Wrapping\n\n" + strings.Repeat(paragraph, 6) +
+		"### Sentinels\n\n" + strings.Repeat(paragraph, 4)
+
+	// when
+	files := renderWindsurf(group, content)
+
+	// then
+	if len(files) < 2 {
+		t.Fatalf("renderWindsurf() returned %d files, want the group to be split", len(files))
+	}
+	var total int
+	for _, file := range files {
+		if size := utf8.RuneCountInString(file.Body); size > windsurfMaxChars {
+			t.Errorf("%s has %d characters, limit is %d", file.Path, size, windsurfMaxChars)
+		}
+		total += strings.Count(file.Body, "Wrap errors with context. \n")
+	}
+	if total != 10 {
+		t.Errorf("parts contain %d paragraphs, want 10", total)
+	}
+}
+
+func TestRenderWindsurfReportsUnmetBudget(t *testing.T) {
+	// given
+	group := RuleGroup{Name: "golang", Description: "Go standards"}
+	content := "# Go\n\n```go\n" + strings.Repeat("// a single code block cannot be split\n\n", 400) + "```\n"
+
+	// when
+	files := renderWindsurf(group, content)
+	_, err := windsurfTarget{}.RenderAggregate([]RuleGroup{group}, []string{content})
+
+	// then
+	if len(files) == 0 {
+		t.Error("renderWindsurf() should render the oversized part anyway")
+	}
+	if !errors.Is(err, errBudgetExceeded) {
+		t.Errorf("RenderAggregate() error = %v, want errBudgetExceeded", err)
+	}
+}
+
+func TestSplitToFit(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		budget   int
+		expected []string
+	}{
+		{
+			name:     "content within budget kept whole",
+			content:  "# Title\n\n## One\n\nText.\n",
+			budget:   100,
+			expected: []string{"# Title\n\n## One\n\nText.\n"},
+		},
+		{
+			name:    "split at H2 outside code",
+			content: "# Title\n\nIntro.\n\n## One\n\n```bash\n## comment in code\n```\n\n## Two\n\nText.\n",
+			budget:  40,
+			expected: []string{
+				"# Title\n\nIntro.\n\n",
+				"## One\n\n```bash\n## comment in code\n```\n\n",
+				"## Two\n\nText.\n",
+			},
+		},
+		{
+			name:    "oversized H2 split at H3, then at paragraphs",
+			content: "## One\n\n### A\n\nShort.\n\n### B\n\nFirst paragraph.\n\n```bash\n\necho kept whole\n```\n",
+			budget:  30,
+			expected: []string{
+				"## One\n\n",
+				"### A\n\nShort.\n\n",
+				"### B\n\n",
+				"First paragraph.\n\n",
+				"```bash\n\necho kept whole\n```\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			sections := splitToFit(tt.content, tt.budget, windsurfBoundaries)
+
+			// then
+			if !reflect.DeepEqual(sections, tt.expected) {
+				t.Errorf("splitToFit()\n  got:  %q\n  want: %q", sections, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// formatFrontmatter marshals the front matter of a generated file between "---" fences,
+// followed by a blank line. Fields are written in struct order and styled after
+// Code-Style/YAML.md: strings in single quotes, or in double quotes when they need escape
+// sequences, and booleans and numbers unquoted. It panics on values yaml.v3 cannot encode,
+// which only a front matter type declared wrong can produce.
+func formatFrontmatter(frontmatter any) string {
+	var node yaml.Node
+	if err := node.Encode(frontmatter); err != nil {
+		panic(fmt.Sprintf("encoding %T front matter: %v", frontmatter, err))
+	}
+	quoteYAMLStrings(&node)
+
+	var sb strings.Builder
+	encoder := yaml.NewEncoder(&sb)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		panic(fmt.Sprintf("encoding %T front matter: %v", frontmatter, err))
+	}
+	if err := encoder.Close(); err != nil {
+		panic(fmt.Sprintf("encoding %T front matter: %v", frontmatter, err))
+	}
+	return frontMatterFence + "\n" + sb.String() + frontMatterFence + "\n\n"
+}
+
+// quoteYAMLStrings sets the quoting style of every string value below node. Mapping keys
+// are left plain.
+func quoteYAMLStrings(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			quoteYAMLStrings(child)
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			quoteYAMLStrings(node.Content[i])
+		}
+	case yaml.ScalarNode:
+		if node.Tag != "!!str" {
+			return
+		}
+		node.Style = yaml.SingleQuotedStyle
+		if strings.IndexFunc(node.Value, func(r rune) bool { return !unicode.IsPrint(r) }) >= 0 {
+			node.Style = yaml.DoubleQuotedStyle
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestFormatFrontmatter(t *testing.T) {
+	tests := []struct {
+		name        string
+		frontmatter any
+		expected    string
+	}{
+		{
+			name:        "strings single-quoted, booleans plain",
+			frontmatter: cursorFrontmatter{Description: "Go standards", AlwaysApply: true},
+			expected:    "---\ndescription: 'Go standards'\nalwaysApply: true\n---\n\n",
+		},
+		{
+			name:        "single quote doubled",
+			frontmatter: cursorFrontmatter{Description: "Don't panic"},
+			expected:    "---\ndescription: 'Don''t panic'\nalwaysApply: false\n---\n\n",
+		},
+		{
+			name:        "double quotes, colons, and backslashes kept literally",
+			frontmatter: cursorFrontmatter{Description: `Say "no": C:\tmp\`},
+			expected:    "---\ndescription: 'Say \"no\": C:\\tmp\\'\nalwaysApply: false\n---\n\n",
+		},
+		{
+			name:        "line breaks escaped in double quotes",
+			frontmatter: cursorFrontmatter{Description: "Rules:\nkeep it short"},
+			expected:    "---\ndescription: \"Rules:\\nkeep it short\"\nalwaysApply: false\n---\n\n",
+		},
+		{
+			name:        "list items quoted",
+			frontmatter: copilotFrontmatter{ApplyTo: []string{"**/*.go", "!**/*_mock.go"}},
+			expected:    "---\napplyTo:\n  - '**/*.go'\n  - '!**/*_mock.go'\n---\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			result := formatFrontmatter(tt.frontmatter)
+
+			// then
+			if result != tt.expected {
+				t.Errorf("formatFrontmatter(%+v)\n  got:  %q\n  want: %q", tt.frontmatter, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGeneratedFrontmatterRoundTrips(t *testing.T) {
+	// given
+	groups, err := loadManifest("rule-groups.yaml")
+	if err != nil {
+		t.Fatalf("loadManifest() error: %v", err)
+	}
+	for _, activation := range activations {
+		group := RuleGroup{
+			Name:        "tricky-" + activation,
+			Description: "Quotes \"double\" and 'single': colons:\nnew lines, #hashes, and C:\\back\\slashes\\",
+			Activation:  activation,
+		}
+		if activation == activationGlob {
+			group.Globs = GlobList{"**/*.{yml,yaml}", "!vendor/**"}
+		}
+		groups = append(groups, group)
+	}
+
+	for _, target := range targets() {
+		for _, group := range groups {
+			t.Run(target.Name()+"/"+group.Name, func(t *testing.T) {
+				// when
+				files := target.RenderGroup(group, "# Content\n")
+
+				// then
+				for _, file := range files {
+					front, _ := splitFrontMatter([]byte(file.Body))
+					if front == nil {
+						continue
+					}
+					var fields map[string]any
+					if err := yaml.Unmarshal(front, &fields); err != nil {
+						t.Fatalf("%s: front matter does not parse: %v\n%s", file.Path, err, front)
+					}
+					if description, ok := fields["description"]; ok && description != group.Description {
+						t.Errorf("%s: description = %q, want %q", file.Path, description, group.Description)
+					}
+					if name, ok := fields["name"]; ok && name != group.Name {
+						t.Errorf("%s: name = %q, want %q", file.Path, name, group.Name)
+					}
+
+					typed := frontmatterOf(t, file.Path)
+					decoder := yaml.NewDecoder(bytes.NewReader(front))
+					decoder.KnownFields(true)
+					if err := decoder.Decode(typed); err != nil {
+						t.Fatalf("%s: front matter does not decode into %T: %v", file.Path, typed, err)
+					}
+					if formatted := formatFrontmatter(reflect.ValueOf(typed).Elem().Interface()); !strings.HasPrefix(file.Body, formatted) {
+						t.Errorf("%s: front matter changes in a round trip\n  got:  %q\n  want prefix of: %q", file.Path, formatted, file.Body)
+					}
+				}
+			})
+		}
+	}
+}
+
+// frontmatterOf returns a pointer to the front matter type written at path.
+func frontmatterOf(t *testing.T, path string) any {
+	t.Helper()
+	types := map[string]any{
+		"claude/rules/":         &claudeRuleFrontmatter{},
+		"claude/skills/":        &claudeSkillFrontmatter{},
+		"cursor/rules/":         &cursorFrontmatter{},
+		"copilot/instructions/": &copilotFrontmatter{},
+		"windsurf/rules/":       &windsurfFrontmatter{},
+		"continue/rules/":       &continueFrontmatter{},
+	}
+	for prefix, frontmatter := range types {
+		if strings.HasPrefix(path, prefix) {
+			return frontmatter
+		}
+	}
+	t.Fatalf("%s has front matter of no known type", path)
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
 	logger "github.com/sirupsen/logrus"
@@ -24,6 +25,37 @@ var imageRegex = regexp.MustCompile(`!\[([^\]]*)\]\(([^)]+)\)`)
 // regex runs.
 var mdLinkTargetRegex = regexp.MustCompile(`\]\((.+?\.md)\)`)
 
+// aiOnlyBlockRegex matches <!-- ai:only -->...<!-- /ai:only --> blocks, whose content is
+// written for AI assistants only. Blocks starting on a line of their own also take the
+// line break after the closing marker.
+var aiOnlyBlockRegex = regexp.MustCompile(`(?ms)^[ \t]*<!--\s*ai:only\s*-->.*?<!--\s*/ai:only\s*-->[ \t]*\n?|<!--\s*ai:only\s*-->.*?<!--\s*/ai:only\s*-->`)
+
+// targetBlockRegex matches <!-- target:claude,cursor -->...<!-- /target --> blocks, whose
+// content only applies to the named AI assistants, in the same two forms as aiOnlyBlockRegex.
+var targetBlockRegex = regexp.MustCompile(`(?ms)^[ \t]*<!--\s*target:[^>]*-->.*?<!--\s*/target(?::[^>]*)?\s*-->[ \t]*\n?|<!--\s*target:[^>]*-->.*?<!--\s*/target(?::[^>]*)?\s*-->`)
+
+// aiMarkerLineRegex matches <!-- ai:exclude --> and <!-- /ai:exclude --> markers on a line of their own.
+var aiMarkerLineRegex = regexp.MustCompile(`(?m)^[ \t]*<!--\s*/?ai:exclude\s*-->[ \t]*\n?`)
+
+// aiMarkerRegex matches the same markers anywhere else in a line.
+var aiMarkerRegex = regexp.MustCompile(`<!--\s*/?ai:exclude\s*-->`)
+
+// codeRegex matches fenced code blocks, up to their closing fence or the end of the page,
+// and inline code spans, in which marker syntax is documented rather than applied.
+var codeRegex = regexp.MustCompile("(?ms)^[ \\t]*```.*?(?:^[ \\t]*```[^\\n]*|\\z)|^[ \\t]*~~~.*?(?:^[ \\t]*~~~[^\\n]*|\\z)|``[^\\n]+?``|`[^`\\n]+`")
+
+// codePlaceholderRegex matches the placeholders stripAIMarkers puts in place of code.
+var codePlaceholderRegex = regexp.MustCompile("\\x00(\\d+)\\x00")
+
+// frontMatterRegex matches YAML front matter at the top of a page: a "---" line followed
+// directly by YAML, up to the next "---" line. A leading thematic break ("---" followed by
+// a blank line, as in _Footer.md) does not match.
+var frontMatterRegex = regexp.MustCompile(`(?s)\A---[ \t]*\r?\n[^\n]*\S.*?\n---[ \t]*(?:\r?\n|\z)`)
+
+// sidecarExcludes are the rsync patterns of diagram sidecars, the text descriptions and Mermaid
+// sources that generate-ai-rules inlines in place of images. The wiki shows the images instead.
+var sidecarExcludes = []string{"*.png.md", "*.jpg.md", "*.jpeg.md", "*.gif.md", "*.svg.md", "*.webp.md", "*.mmd"}
+
 func main() {
 	// GITHUB_REPOSITORY is automatically set by GitHub Actions (e.g., "rios0rios0/guide")
 	githubRepo := os.Getenv("GITHUB_REPOSITORY")
@@ -44,9 +76,14 @@ func main() {
 	}
 
 	// copy files and folders from root to "wikiDir" directory, excluding .git and .github folders
+	// and the diagram sidecars
+	excludes := ""
+	for _, pattern := range sidecarExcludes {
+		excludes += fmt.Sprintf("--exclude='%s' ", pattern)
+	}
 	err = exec.Command("sh", "-c",
 		fmt.Sprintf("rsync -av --exclude='.git' --exclude='.github' --exclude='%s' "+
-			"--exclude='.editorconfig' --exclude='README.md' ./ %s", wikiDir, wikiDir)).Run()
+			"--exclude='.editorconfig' --exclude='README.md' %s./ %s", wikiDir, excludes, wikiDir)).Run()
 	if err != nil {
 		logger.Errorf("Error copying files: %v\n", err)
 		return
@@ -84,6 +121,8 @@ func processFile(path string, rawBaseURL string) {
 	fileDir := filepath.Dir(relPath)
 
 	text := string(content)
+	text = stripFrontMatter(text)
+	text = stripAIMarkers(text)
 	text = replaceImages(text, fileDir, rawBaseURL)
 	text = replaceLinks(text)
 
@@ -94,6 +133,46 @@ func processFile(path string, rawBaseURL string) {
 	}
 }
 
+// stripFrontMatter removes the YAML front matter that tells generate-ai-rules how to turn
+// a page into rules, so it never renders on the wiki.
+//
+// Example:
+//
+//	---\nai:\n  group: 'yaml'\n---\n# YAML\n  -> # YAML\n
+func stripFrontMatter(text string) string {
+	return frontMatterRegex.ReplaceAllString(text, "")
+}
+
+// stripAIMarkers removes the markers that scope content to the generated AI rules, so they
+// never appear on the wiki. Content marked <!-- ai:exclude --> is written for humans and is
+// kept; content marked <!-- ai:only --> or scoped to some assistants with <!-- target:... -->
+// is written for assistants and is removed with its markers. Markers inside fenced code
+// blocks and inline code are kept, so pages can document the marker syntax.
+//
+// Examples:
+//
+//	<!-- ai:exclude -->\nHistory.\n<!-- /ai:exclude -->\n  -> History.\n
+//	Rule.<!-- ai:only --> Prefer small diffs.<!-- /ai:only -->  -> Rule.
+//	Write `<!-- ai:only -->` before AI-only text.  -> (unchanged)
+func stripAIMarkers(text string) string {
+	// set code aside behind placeholders, which are removed with any block they fall in
+	var code []string
+	text = codeRegex.ReplaceAllStringFunc(text, func(match string) string {
+		code = append(code, match)
+		return fmt.Sprintf("\x00%d\x00", len(code)-1)
+	})
+
+	text = aiOnlyBlockRegex.ReplaceAllString(text, "")
+	text = targetBlockRegex.ReplaceAllString(text, "")
+	text = aiMarkerLineRegex.ReplaceAllString(text, "")
+	text = aiMarkerRegex.ReplaceAllString(text, "")
+
+	return codePlaceholderRegex.ReplaceAllStringFunc(text, func(match string) string {
+		index, _ := strconv.Atoi(codePlaceholderRegex.FindStringSubmatch(match)[1])
+		return code[index]
+	})
+}
+
 // replaceImages converts markdown image syntax to GitHub Wiki image syntax with
 // absolute URLs. This is necessary because GitHub Wiki renders pages as flat URLs,
 // so relative image paths do not resolve correctly.
@@ -88,6 +88,109 @@ func TestReplaceLinks(t *testing.T) {
 	}
 }
 
+func TestStripAIMarkers(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "exclude markers removed and content kept",
+			input:    "Rule.\n\n<!-- ai:exclude -->\nSome history.\n<!-- /ai:exclude -->\n\nNext rule.\n",
+			expected: "Rule.\n\nSome history.\n\nNext rule.\n",
+		},
+		{
+			name:     "ai-only block removed with its content",
+			input:    "Rule.\n\n<!-- ai:only -->\nPrefer the smallest diff.\n<!-- /ai:only -->\n\nNext rule.\n",
+			expected: "Rule.\n\n\nNext rule.\n",
+		},
+		{
+			name:     "inline markers removed",
+			input:    "Use tabs<!-- ai:exclude --> (since 2019)<!-- /ai:exclude -->.<!-- ai:only --> Always.<!-- /ai:only -->\n",
+			expected: "Use tabs (since 2019).\n",
+		},
+		{
+			name:     "target blocks removed with their content",
+			input:    "Rule.\n\n<!-- target:claude,cursor -->\nUse the changelog-guard hook.\n<!-- /target -->\n\nNext rule.\n",
+			expected: "Rule.\n\n\nNext rule.\n",
+		},
+		{
+			name:     "markers in fenced code kept",
+			input:    "Example:\n\n```markdown\n<!-- ai:only -->\nPrefer the smallest diff.\n<!-- /ai:only -->\n<!-- target:claude -->\n<!-- ai:exclude -->\n```\n\nNext rule.\n",
+			expected: "Example:\n\n```markdown\n<!-- ai:only -->\nPrefer the smallest diff.\n<!-- /ai:only -->\n<!-- target:claude -->\n<!-- ai:exclude -->\n```\n\nNext rule.\n",
+		},
+		{
+			name:     "markers in inline code kept",
+			input:    "Wrap text in `<!-- ai:only -->` and `<!-- /ai:only -->`, or ``<!-- ai:exclude -->``.\n",
+			expected: "Wrap text in `<!-- ai:only -->` and `<!-- /ai:only -->`, or ``<!-- ai:exclude -->``.\n",
+		},
+		{
+			name:     "code inside an ai-only block removed with it",
+			input:    "Rule.\n<!-- ai:only -->\nRun `make lint`.\n\n```bash\nmake lint\n```\n<!-- /ai:only -->\nNext rule.\n",
+			expected: "Rule.\nNext rule.\n",
+		},
+		{
+			name:     "other comments unchanged",
+			input:    "<!-- TODO: expand -->\nText.\n",
+			expected: "<!-- TODO: expand -->\nText.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			input := tt.input
+
+			// when
+			result := stripAIMarkers(input)
+
+			// then
+			if result != tt.expected {
+				t.Errorf("stripAIMarkers(%q)\n  got:  %q\n  want: %q", input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStripFrontMatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "front matter removed",
+			input:    "---\nai:\n  group: 'yaml'\n  priority: 1\n---\n# YAML\n\n---\n\nText.\n",
+			expected: "# YAML\n\n---\n\nText.\n",
+		},
+		{
+			name:     "leading thematic break kept",
+			input:    "---\n\n<p align=\"center\">Footer</p>\n\n---\n",
+			expected: "---\n\n<p align=\"center\">Footer</p>\n\n---\n",
+		},
+		{
+			name:     "page without front matter unchanged",
+			input:    "# Title\n\nText.\n",
+			expected: "# Title\n\nText.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			input := tt.input
+
+			// when
+			result := stripFrontMatter(input)
+
+			// then
+			if result != tt.expected {
+				t.Errorf("stripFrontMatter(%q)\n  got:  %q\n  want: %q", input, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestReplaceImages(t *testing.T) {
 	tests := []struct {
 		name     string